@@ -0,0 +1,423 @@
+package command
+
+import (
+  "bytes"
+  "encoding/json"
+  "fmt"
+  "github.com/npathai/github-cli-clone/git"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+  "io"
+  "io/ioutil"
+  "net/http"
+  "net/url"
+  "os"
+  "os/exec"
+  "regexp"
+  "sort"
+  "strconv"
+  "strings"
+)
+
+func init() {
+  RootCmd.AddCommand(apiCmd)
+
+  apiCmd.Flags().StringP("method", "X", "", "The HTTP method for the request (default: GET, or POST when fields are given)")
+  apiCmd.Flags().StringArrayP("raw-field", "f", nil, "Add a string parameter in `key=value` format")
+  apiCmd.Flags().StringArrayP("field", "F", nil, "Add a typed parameter in `key=value` format")
+  apiCmd.Flags().StringArrayP("header", "H", nil, "Add a HTTP request header in `key:value` format")
+  apiCmd.Flags().Bool("paginate", false, "Make additional requests to fetch all pages of results")
+  apiCmd.Flags().BoolP("include", "i", false, "Include the HTTP response status line and headers in the output")
+  apiCmd.Flags().StringP("jq", "q", "", "Filter the response with a jq `expression` (requires jq)")
+}
+
+var apiCmd = &cobra.Command{
+  Use: "api <endpoint>",
+  Short: "Make an authenticated GitHub API request",
+  Long: `Make an authenticated request to the GitHub API and print the response.
+
+The endpoint is a path such as "repos/{owner}/{repo}/releases", or "graphql"
+for the GraphQL API. The placeholders {owner}, {repo} and {branch} are
+filled in from the repository of the current directory.
+
+Fields given with -f are sent as strings. Fields given with -F are converted:
+"true", "false" and "null" become JSON literals, integers become numbers, and
+a value starting with "@" is replaced by the contents of the named file ("@-"
+reads standard input). Fields are sent as query parameters for GET requests
+and as a JSON body otherwise. For "graphql", the "query" field holds the
+query and all other fields become its variables.
+
+With --paginate, the pages are combined into a single JSON document: arrays
+are concatenated, and for objects such as search results the arrays they
+hold are concatenated while the other members are taken from the first page.`,
+  Args: cobra.ExactArgs(1),
+  RunE: api,
+}
+
+func api(cmd *cobra.Command, args []string) error {
+  method, _ := cmd.Flags().GetString("method")
+  rawFields, _ := cmd.Flags().GetStringArray("raw-field")
+  typedFields, _ := cmd.Flags().GetStringArray("field")
+  headerArgs, _ := cmd.Flags().GetStringArray("header")
+  paginate, _ := cmd.Flags().GetBool("paginate")
+  include, _ := cmd.Flags().GetBool("include")
+  jq, _ := cmd.Flags().GetString("jq")
+
+  placeholders := &apiPlaceholders{}
+  path, err := placeholders.fill(strings.TrimPrefix(args[0], "/"))
+  if err != nil {
+    return err
+  }
+  isGraphQL := path == "graphql"
+
+  params, err := apiFields(rawFields, typedFields, placeholders)
+  if err != nil {
+    return err
+  }
+
+  if method == "" {
+    method = "GET"
+    if len(params) > 0 || isGraphQL {
+      method = "POST"
+    }
+  }
+  method = strings.ToUpper(method)
+  if paginate && method != "GET" {
+    return fmt.Errorf("--paginate is only supported for GET requests")
+  }
+
+  headers := http.Header{}
+  for _, h := range headerArgs {
+    i := strings.Index(h, ":")
+    if i < 0 {
+      return fmt.Errorf("header %q is not in key:value format", h)
+    }
+    headers.Add(strings.TrimSpace(h[:i]), strings.TrimSpace(h[i+1:]))
+  }
+
+  var body io.Reader
+  if isGraphQL {
+    body, err = graphQLBody(params)
+    if err != nil {
+      return err
+    }
+  } else if method == "GET" {
+    path = addQueryParams(path, params)
+  } else if len(params) > 0 {
+    data, err := json.Marshal(params)
+    if err != nil {
+      return err
+    }
+    body = bytes.NewReader(data)
+  }
+  if body != nil && headers.Get("Content-Type") == "" {
+    headers.Set("Content-Type", "application/json; charset=utf-8")
+  }
+
+  host := github.DefaultGitHubHost()
+  if project, err := placeholders.project(); err == nil {
+    host = project.Host
+  }
+  client := github.NewClient(host)
+
+  pages := [][]byte{}
+  for {
+    res, err := client.Request(method, path, body, headers)
+    if err != nil {
+      return err
+    }
+
+    data, err := ioutil.ReadAll(res.Body)
+    res.Body.Close()
+    if err != nil {
+      return err
+    }
+
+    if include {
+      printResponseHeaders(res)
+    }
+
+    if paginate && res.StatusCode < 400 {
+      pages = append(pages, data)
+      if path = nextPageURL(res.Header.Get("Link")); path != "" {
+        continue
+      }
+      if data, err = mergeJSONPages(pages); err != nil {
+        return err
+      }
+    }
+
+    if err := printResponseBody(data, res.Header.Get("Content-Type"), jq); err != nil {
+      return err
+    }
+
+    if res.StatusCode >= 400 {
+      ui.Errorf("gh: %s (HTTP %d)\n", apiErrorMessage(data, res), res.StatusCode)
+      return &ExitError{Code: 1}
+    }
+    return nil
+  }
+}
+
+// mergeJSONPages combines the pages of a paginated response into one JSON
+// document. Array pages are concatenated. For object pages, array members
+// are concatenated and the other members are taken from the first page.
+func mergeJSONPages(pages [][]byte) ([]byte, error) {
+  if len(pages) == 1 {
+    return pages[0], nil
+  }
+
+  var merged interface{}
+  for i, page := range pages {
+    var value interface{}
+    decoder := json.NewDecoder(bytes.NewReader(page))
+    decoder.UseNumber()
+    if err := decoder.Decode(&value); err != nil {
+      return nil, fmt.Errorf("unable to combine page %d of the response: %s", i+1, err)
+    }
+
+    if i == 0 {
+      merged = value
+      continue
+    }
+    switch m := merged.(type) {
+    case []interface{}:
+      items, ok := value.([]interface{})
+      if !ok {
+        return nil, fmt.Errorf("unable to combine page %d of the response: expected an array", i+1)
+      }
+      merged = append(m, items...)
+    case map[string]interface{}:
+      object, ok := value.(map[string]interface{})
+      if !ok {
+        return nil, fmt.Errorf("unable to combine page %d of the response: expected an object", i+1)
+      }
+      for key, items := range object {
+        if existing, ok := m[key].([]interface{}); ok {
+          if items, ok := items.([]interface{}); ok {
+            m[key] = append(existing, items...)
+          }
+        }
+      }
+    default:
+      return nil, fmt.Errorf("unable to combine page %d of the response: expected an array or an object", i+1)
+    }
+  }
+
+  var buf bytes.Buffer
+  encoder := json.NewEncoder(&buf)
+  encoder.SetEscapeHTML(false)
+  if err := encoder.Encode(merged); err != nil {
+    return nil, err
+  }
+  return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// apiPlaceholders resolves {owner}, {repo} and {branch} on demand, so that
+// endpoints without placeholders also work outside of a git repository.
+type apiPlaceholders struct {
+  cachedProject *github.Project
+}
+
+var placeholderRegex = regexp.MustCompile(`\{(owner|repo|branch)\}`)
+
+func (p *apiPlaceholders) project() (*github.Project, error) {
+  if p.cachedProject == nil {
+    project, err := project()
+    if err != nil {
+      return nil, err
+    }
+    p.cachedProject = project
+  }
+  return p.cachedProject, nil
+}
+
+func (p *apiPlaceholders) fill(value string) (string, error) {
+  var err error
+  filled := placeholderRegex.ReplaceAllStringFunc(value, func(placeholder string) string {
+    if err != nil {
+      return placeholder
+    }
+
+    if placeholder == "{branch}" {
+      var branch string
+      branch, err = git.CurrentBranch()
+      return branch
+    }
+
+    var project *github.Project
+    project, err = p.project()
+    if err != nil {
+      return placeholder
+    }
+    if placeholder == "{owner}" {
+      return project.Owner
+    }
+    return project.Name
+  })
+  return filled, err
+}
+
+func apiFields(rawFields, typedFields []string, placeholders *apiPlaceholders) (map[string]interface{}, error) {
+  params := map[string]interface{}{}
+  for _, f := range rawFields {
+    key, value, err := splitField(f)
+    if err != nil {
+      return nil, err
+    }
+    params[key] = value
+  }
+
+  for _, f := range typedFields {
+    key, value, err := splitField(f)
+    if err != nil {
+      return nil, err
+    }
+    params[key], err = typedFieldValue(value, placeholders)
+    if err != nil {
+      return nil, err
+    }
+  }
+  return params, nil
+}
+
+func splitField(field string) (string, string, error) {
+  i := strings.Index(field, "=")
+  if i <= 0 {
+    return "", "", fmt.Errorf("field %q is not in key=value format", field)
+  }
+  return field[:i], field[i+1:], nil
+}
+
+func typedFieldValue(value string, placeholders *apiPlaceholders) (interface{}, error) {
+  switch {
+  case value == "true":
+    return true, nil
+  case value == "false":
+    return false, nil
+  case value == "null":
+    return nil, nil
+  case strings.HasPrefix(value, "@"):
+    var data []byte
+    var err error
+    if value == "@-" {
+      data, err = ioutil.ReadAll(os.Stdin)
+    } else {
+      data, err = ioutil.ReadFile(value[1:])
+    }
+    return string(data), err
+  }
+
+  if n, err := strconv.Atoi(value); err == nil {
+    return n, nil
+  }
+  return placeholders.fill(value)
+}
+
+func graphQLBody(params map[string]interface{}) (io.Reader, error) {
+  payload := map[string]interface{}{}
+  variables := map[string]interface{}{}
+  for key, value := range params {
+    if key == "query" {
+      payload[key] = value
+    } else {
+      variables[key] = value
+    }
+  }
+  if _, ok := payload["query"]; !ok {
+    return nil, fmt.Errorf("a query field is required for graphql requests")
+  }
+  if len(variables) > 0 {
+    payload["variables"] = variables
+  }
+
+  data, err := json.Marshal(payload)
+  if err != nil {
+    return nil, err
+  }
+  return bytes.NewReader(data), nil
+}
+
+func addQueryParams(path string, params map[string]interface{}) string {
+  if len(params) == 0 {
+    return path
+  }
+
+  query := url.Values{}
+  for key, value := range params {
+    if value == nil {
+      query.Add(key, "")
+    } else {
+      query.Add(key, fmt.Sprint(value))
+    }
+  }
+
+  sep := "?"
+  if strings.Contains(path, "?") {
+    sep = "&"
+  }
+  return path + sep + query.Encode()
+}
+
+var linkNextRegex = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+func nextPageURL(link string) string {
+  if match := linkNextRegex.FindStringSubmatch(link); match != nil {
+    return match[1]
+  }
+  return ""
+}
+
+func printResponseHeaders(res *http.Response) {
+  ui.Printf("%s %s\n", res.Proto, res.Status)
+
+  names := make([]string, 0, len(res.Header))
+  for name := range res.Header {
+    names = append(names, name)
+  }
+  sort.Strings(names)
+  for _, name := range names {
+    ui.Printf("%s: %s\n", ui.Cyan(name), strings.Join(res.Header[name], ", "))
+  }
+  ui.Println("")
+}
+
+// printResponseBody writes the response, through jq when a filter is given.
+// JSON is indented when writing to a terminal.
+func printResponseBody(data []byte, contentType, jq string) error {
+  if jq != "" {
+    jqPath, err := exec.LookPath("jq")
+    if err != nil {
+      return fmt.Errorf("--jq requires the jq program to be installed")
+    }
+    jqCmd := exec.Command(jqPath, "-r", jq)
+    jqCmd.Stdin = bytes.NewReader(data)
+    jqCmd.Stdout = ui.Stdout
+    jqCmd.Stderr = ui.Stderr
+    if err := jqCmd.Run(); err != nil {
+      return fmt.Errorf("error running jq: %s", err)
+    }
+    return nil
+  }
+
+  if ui.IsTerminal(os.Stdout) && strings.Contains(contentType, "json") {
+    var indented bytes.Buffer
+    if err := json.Indent(&indented, data, "", "  "); err == nil {
+      indented.WriteString("\n")
+      data = indented.Bytes()
+    }
+  }
+  _, err := ui.Stdout.Write(data)
+  return err
+}
+
+func apiErrorMessage(data []byte, res *http.Response) string {
+  var body struct {
+    Message string `json:"message"`
+  }
+  if json.Unmarshal(data, &body) == nil && body.Message != "" {
+    return body.Message
+  }
+  return strings.TrimPrefix(res.Status, strconv.Itoa(res.StatusCode)+" ")
+}
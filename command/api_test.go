@@ -0,0 +1,95 @@
+package command
+
+import (
+  "bytes"
+  "fmt"
+  "github.com/npathai/github-cli-clone/ui"
+  "net/http"
+  "testing"
+)
+
+func TestNextPageURL(t *testing.T) {
+  tests := []struct {
+    link string
+    want string
+  }{
+    {"", ""},
+    {`<https://api.github.com/repositories/1/issues?page=2>; rel="next", <https://api.github.com/repositories/1/issues?page=5>; rel="last"`, "https://api.github.com/repositories/1/issues?page=2"},
+    {`<https://api.github.com/repositories/1/issues?page=1>; rel="prev", <https://api.github.com/repositories/1/issues?page=3>; rel="next"`, "https://api.github.com/repositories/1/issues?page=3"},
+    {`<https://api.github.com/repositories/1/issues?page=1>; rel="first"`, ""},
+  }
+
+  for _, tt := range tests {
+    if got := nextPageURL(tt.link); got != tt.want {
+      t.Errorf("nextPageURL(%q) = %q, want %q", tt.link, got, tt.want)
+    }
+  }
+}
+
+func TestMergeJSONPages(t *testing.T) {
+  tests := []struct {
+    name  string
+    pages []string
+    want  string
+  }{
+    {"single page", []string{`[1, 2]`}, `[1, 2]`},
+    {"arrays", []string{`[{"id": 1}]`, `[{"id": 2}, {"id": 3}]`, `[]`}, `[{"id":1},{"id":2},{"id":3}]`},
+    {"objects", []string{`{"total_count": 3, "items": [1, 2]}`, `{"total_count": 3, "items": [3]}`}, `{"items":[1,2,3],"total_count":3}`},
+    {"large numbers and markup", []string{`[12345678901234567890]`, `["<a&b>"]`}, `[12345678901234567890,"<a&b>"]`},
+  }
+
+  for _, tt := range tests {
+    pages := [][]byte{}
+    for _, page := range tt.pages {
+      pages = append(pages, []byte(page))
+    }
+    got, err := mergeJSONPages(pages)
+    if err != nil {
+      t.Errorf("%s: mergeJSONPages() error: %s", tt.name, err)
+    } else if string(got) != tt.want {
+      t.Errorf("%s: mergeJSONPages() = %s, want %s", tt.name, got, tt.want)
+    }
+  }
+
+  if _, err := mergeJSONPages([][]byte{[]byte(`[1]`), []byte(`{"a": 1}`)}); err == nil {
+    t.Error("mergeJSONPages() of an array and an object succeeded, want an error")
+  }
+}
+
+func TestAPIPaginate(t *testing.T) {
+  requests := []string{}
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    requests = append(requests, r.URL.RequestURI())
+    switch r.URL.Query().Get("page") {
+    case "":
+      w.Header().Set("Link", `<https://api.github.com/repositories/1/issues?page=2>; rel="next", <https://api.github.com/repositories/1/issues?page=3>; rel="last"`)
+      fmt.Fprint(w, `[{"number": 1}, {"number": 2}]`)
+    case "2":
+      w.Header().Set("Link", `<https://api.github.com/repositories/1/issues?page=3>; rel="next"`)
+      fmt.Fprint(w, `[{"number": 3}]`)
+    default:
+      w.Header().Set("Link", `<https://api.github.com/repositories/1/issues?page=2>; rel="prev"`)
+      fmt.Fprint(w, `[{"number": 4}]`)
+    }
+  }
+  defer stubAPI(t, "", handler)()
+
+  var out bytes.Buffer
+  stdout := ui.Stdout
+  ui.Stdout = &out
+  defer func() { ui.Stdout = stdout }()
+
+  defer setFlags(t, apiCmd, map[string]string{"paginate": "true"})()
+
+  if err := api(apiCmd, []string{"repos/octo/hello/issues"}); err != nil {
+    t.Fatalf("api() error: %s", err)
+  }
+
+  wantRequests := []string{"/repos/octo/hello/issues", "/repositories/1/issues?page=2", "/repositories/1/issues?page=3"}
+  if fmt.Sprint(requests) != fmt.Sprint(wantRequests) {
+    t.Errorf("requests = %q, want %q", requests, wantRequests)
+  }
+  if want := `[{"number":1},{"number":2},{"number":3},{"number":4}]`; out.String() != want {
+    t.Errorf("output = %s, want %s", out.String(), want)
+  }
+}
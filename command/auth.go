@@ -0,0 +1,447 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+  "io/ioutil"
+  "os"
+  "strings"
+)
+
+func init() {
+  RootCmd.AddCommand(authCmd)
+  authCmd.AddCommand(authLoginCmd)
+  authCmd.AddCommand(authLogoutCmd)
+  authCmd.AddCommand(authStatusCmd)
+  authCmd.AddCommand(authRefreshCmd)
+  authCmd.AddCommand(authTokenCmd)
+  authCmd.AddCommand(authSwitchCmd)
+
+  for _, cmd := range []*cobra.Command{authLoginCmd, authLogoutCmd, authStatusCmd, authRefreshCmd, authTokenCmd, authSwitchCmd} {
+    cmd.Flags().String("hostname", "", "The `hostname` of the GitHub instance")
+  }
+  for _, cmd := range []*cobra.Command{authLogoutCmd, authTokenCmd, authSwitchCmd} {
+    cmd.Flags().StringP("user", "u", "", "The `login` of the account")
+  }
+  authLoginCmd.Flags().Bool("with-token", false, "Read the token from standard input")
+  authStatusCmd.Flags().BoolP("show-token", "t", false, "Display the auth token")
+}
+
+var authCmd = &cobra.Command{
+  Use: "auth",
+  Short: "Log in, log out and check authentication state",
+  Long: "This command allows you to manage the credentials used to talk to GitHub",
+  Args: cobra.MinimumNArgs(1),
+}
+
+var authLoginCmd = &cobra.Command{
+  Use: "login",
+  Short: "Authenticate with a GitHub host",
+  Long: `Authenticate with a GitHub host and store the credentials.
+
+With --with-token, a personal access token is read from standard input, as in
+"gh auth login --with-token < token.txt". Otherwise you are prompted to
+authorize in your browser. GitHub Enterprise hosts need the client ID of an
+OAuth app with device flow enabled, set with oauth_client_id for the host in
+the configuration or with the HUB_OAUTH_CLIENT_ID environment variable, which
+also replace the default app on github.com.`,
+  Args: cobra.NoArgs,
+  RunE: authLogin,
+}
+
+var authLogoutCmd = &cobra.Command{
+  Use: "logout",
+  Short: "Remove the credentials of a GitHub account",
+  Long: `Remove the stored credentials of an account on a GitHub host, by default
+the active one.
+
+The token itself is not revoked; that can be done from the settings of your
+GitHub account.`,
+  Args: cobra.NoArgs,
+  RunE: authLogout,
+}
+
+var authStatusCmd = &cobra.Command{
+  Use: "status",
+  Short: "View authentication status",
+  Long: `Verify the stored credentials of every configured host and show the
+scopes granted to their tokens.`,
+  Args: cobra.NoArgs,
+  RunE: authStatus,
+}
+
+var authRefreshCmd = &cobra.Command{
+  Use: "refresh",
+  Short: "Authorize again to replace the stored token of a host",
+  Args: cobra.NoArgs,
+  RunE: authRefresh,
+}
+
+var authTokenCmd = &cobra.Command{
+  Use: "token",
+  Short: "Print the auth token of a host",
+  Args: cobra.NoArgs,
+  RunE: authToken,
+}
+
+var authSwitchCmd = &cobra.Command{
+  Use: "switch",
+  Short: "Switch the active account of a host",
+  Long: `Make another account logged in to a GitHub host the active one.
+
+Without --user, this toggles between the two accounts of a host. A repository
+can be tied to an account regardless of the active one with
+"git config gh.user <login>".`,
+  Args: cobra.NoArgs,
+  RunE: authSwitch,
+}
+
+func authHostname(cmd *cobra.Command) string {
+  if hostname, _ := cmd.Flags().GetString("hostname"); hostname != "" {
+    return strings.ToLower(hostname)
+  }
+  return github.DefaultGitHubHost()
+}
+
+func authLogin(cmd *cobra.Command, args []string) error {
+  hostname := authHostname(cmd)
+  withToken, _ := cmd.Flags().GetBool("with-token")
+  config := github.CurrentConfig()
+
+  if !withToken {
+    return authorizeHost(config, hostname)
+  }
+
+  data, err := ioutil.ReadAll(os.Stdin)
+  if err != nil {
+    return err
+  }
+  token := strings.TrimSpace(string(data))
+  if token == "" {
+    return fmt.Errorf("no token found on standard input")
+  }
+
+  host := newAccount(config, hostname)
+  host.AccessToken = token
+
+  user, _, err := github.NewClientWithHost(host).CurrentUserScopes()
+  if err != nil {
+    return fmt.Errorf("error validating token: %s", err)
+  }
+  host.User = user.Login
+
+  config.AddAccount(host)
+  if err := config.Save(); err != nil {
+    return err
+  }
+
+  ui.Errorf("%s Logged in to %s as %s\n", ui.Green("✓"), hostname, ui.Bold(host.User))
+  return nil
+}
+
+// newAccount returns an account for the host that shares the connection
+// settings of the accounts already configured for it.
+func newAccount(config *github.Config, hostname string) *github.Host {
+  host := &github.Host{Host: hostname, Protocol: "https"}
+  if existing := config.ActiveAccount(hostname); existing != nil {
+    host.Protocol = existing.Protocol
+    host.UnixSocket = existing.UnixSocket
+    host.OAuthClientID = existing.OAuthClientID
+  }
+  return host
+}
+
+// authorizeHost runs the interactive authorization for the host and makes
+// the authorized account the active one, replacing the credentials stored
+// for the same user.
+func authorizeHost(config *github.Config, hostname string) error {
+  if _, source := config.DetectToken(hostname); source != "" {
+    return fmt.Errorf("The value of the %s environment variable is being used for authentication.\n" +
+      "To have gh store credentials instead, first clear the value from the environment.", source)
+  }
+
+  host := newAccount(config, hostname)
+  if err := config.Authorize(host); err != nil {
+    return err
+  }
+
+  config.AddAccount(host)
+  if err := config.Save(); err != nil {
+    return err
+  }
+
+  ui.Errorf("%s Logged in to %s as %s\n", ui.Green("✓"), hostname, ui.Bold(host.User))
+  return nil
+}
+
+// authAccount returns the account picked with --user on the host, or the
+// account in use when no user is given.
+func authAccount(cmd *cobra.Command, config *github.Config, hostname string) (*github.Host, error) {
+  user, _ := cmd.Flags().GetString("user")
+  if user == "" {
+    if host := config.Find(hostname); host != nil {
+      return host, nil
+    }
+    return nil, fmt.Errorf("not logged in to %s", hostname)
+  }
+
+  if host := config.FindUser(hostname, user); host != nil {
+    return host, nil
+  }
+  return nil, fmt.Errorf("no account %s found for %s", user, hostname)
+}
+
+func authLogout(cmd *cobra.Command, args []string) error {
+  config := github.CurrentConfig()
+
+  hostname, _ := cmd.Flags().GetString("hostname")
+  if hostname == "" {
+    hostnames := configuredHostnames(config)
+    switch len(hostnames) {
+    case 0:
+      return fmt.Errorf("not logged in to any hosts")
+    case 1:
+      hostname = hostnames[0]
+    default:
+      return fmt.Errorf("logged in to several hosts; use --hostname to pick one")
+    }
+  }
+  hostname = strings.ToLower(hostname)
+
+  account, err := authAccount(cmd, config, hostname)
+  if err != nil {
+    return err
+  }
+  host := config.Remove(hostname, account.User)
+  if err := config.Save(); err != nil {
+    return err
+  }
+
+  ui.Errorf("%s Logged out of %s account %s\n", ui.Green("✓"), host.Host, ui.Bold(host.User))
+  if active := config.ActiveAccount(hostname); active != nil && host.Active {
+    ui.Errorf("%s Switched active account for %s to %s\n", ui.Green("✓"), hostname, ui.Bold(active.User))
+  }
+  return nil
+}
+
+func configuredHostnames(config *github.Config) []string {
+  hostnames := []string{}
+  for _, h := range config.Hosts {
+    hostnames = appendUnique(hostnames, h.Host)
+  }
+  return hostnames
+}
+
+func authStatus(cmd *cobra.Command, args []string) error {
+  config := github.CurrentConfig()
+  hostname, _ := cmd.Flags().GetString("hostname")
+  showToken, _ := cmd.Flags().GetBool("show-token")
+
+  entries := authEntries(config, strings.ToLower(hostname))
+  if len(entries) == 0 {
+    if hostname != "" {
+      ui.Errorf("You are not logged in to %s. Run `gh auth login --hostname %s` to authenticate.\n", hostname, hostname)
+    } else {
+      ui.Errorf("You are not logged in to any GitHub hosts. Run `gh auth login` to authenticate.\n")
+    }
+    return &ExitError{Code: 1}
+  }
+
+  perHost := map[string]int{}
+  for _, e := range entries {
+    perHost[e.Host.Host]++
+  }
+
+  failed := false
+  for i, e := range entries {
+    h := e.Host
+    if i == 0 || entries[i-1].Host.Host != h.Host {
+      ui.Println(ui.Bold(h.Host))
+    } else {
+      ui.Println()
+    }
+
+    if h.AccessToken == "" {
+      ui.Printf("  %s No token stored for %s\n", ui.Red("X"), h.User)
+      failed = true
+      continue
+    }
+
+    // Validate a copy so that an invalid token does not trigger a prompt.
+    host := *h
+    user, scopes, err := github.NewClientWithHost(&host).CurrentUserScopes()
+    if err != nil {
+      ui.Printf("  %s %s: authentication failed using the token from %s\n", ui.Red("X"), h.Host, e.Source)
+      ui.Printf("  - %s\n", err)
+      failed = true
+      continue
+    }
+
+    ui.Printf("  %s Logged in to %s as %s\n", ui.Green("✓"), h.Host, ui.Bold(user.Login))
+    if h.Protocol != "" {
+      ui.Printf("  %s Git operations protocol: %s\n", ui.Green("✓"), h.Protocol)
+    }
+    token := h.AccessToken
+    if !showToken {
+      token = maskToken(token)
+    }
+    ui.Printf("  %s Token: %s (from %s)\n", ui.Green("✓"), token, e.Source)
+    if len(scopes) > 0 {
+      ui.Printf("  %s Token scopes: %s\n", ui.Green("✓"), strings.Join(scopes, ", "))
+    } else {
+      ui.Printf("  %s Token scopes: none\n", ui.Yellow("!"))
+    }
+    if perHost[h.Host] > 1 {
+      ui.Printf("  - Active account: %s\n", e.Active)
+    }
+  }
+
+  if failed {
+    return &ExitError{Code: 1}
+  }
+  return nil
+}
+
+// authEntry is an account listed by auth status along with where its token
+// comes from and whether it is the one in use.
+type authEntry struct {
+  Host   *github.Host
+  Source string
+  Active string
+}
+
+// authEntries lists the accounts of every host, or only of hostname when it
+// is given. A token set in the environment takes precedence over the stored
+// accounts of its host and is listed first.
+func authEntries(config *github.Config, hostname string) []authEntry {
+  hostnames := configuredHostnames(config)
+  for _, name := range []string{github.GitHubHost, github.DefaultGitHubHost(), hostname} {
+    if token, _ := config.DetectToken(name); name != "" && token != "" {
+      hostnames = appendUnique(hostnames, name)
+    }
+  }
+
+  entries := []authEntry{}
+  for _, name := range hostnames {
+    if hostname != "" && name != hostname {
+      continue
+    }
+
+    token, source := config.DetectToken(name)
+    if token != "" {
+      host := &github.Host{Host: name, AccessToken: token, Protocol: "https"}
+      if account := config.ActiveAccount(name); account != nil {
+        host.Protocol, host.UnixSocket = account.Protocol, account.UnixSocket
+      }
+      entries = append(entries, authEntry{Host: host, Source: source, Active: "true"})
+    }
+
+    for _, h := range config.Accounts(name) {
+      active := "false"
+      if token == "" && config.Find(name) == h {
+        active = "true"
+        if !h.Active {
+          active = "true (set by git config gh.user)"
+        }
+      }
+      entries = append(entries, authEntry{Host: h, Source: tokenSource(h), Active: active})
+    }
+  }
+  return entries
+}
+
+func tokenSource(h *github.Host) string {
+  if h.CredentialStore == "" {
+    return "the config file"
+  }
+  return fmt.Sprintf("the %s credential store", h.CredentialStore)
+}
+
+func appendUnique(values []string, value string) []string {
+  for _, v := range values {
+    if v == value {
+      return values
+    }
+  }
+  return append(values, value)
+}
+
+func maskToken(token string) string {
+  if len(token) <= 4 {
+    return strings.Repeat("*", len(token))
+  }
+  return token[:4] + strings.Repeat("*", len(token)-4)
+}
+
+func authRefresh(cmd *cobra.Command, args []string) error {
+  hostname := authHostname(cmd)
+  config := github.CurrentConfig()
+  if config.Find(hostname) == nil {
+    return fmt.Errorf("not logged in to %s; use `gh auth login` instead", hostname)
+  }
+  return authorizeHost(config, hostname)
+}
+
+func authToken(cmd *cobra.Command, args []string) error {
+  hostname := authHostname(cmd)
+  config := github.CurrentConfig()
+
+  if user, _ := cmd.Flags().GetString("user"); user == "" {
+    if token, _ := config.DetectToken(hostname); token != "" {
+      ui.Println(token)
+      return nil
+    }
+  }
+
+  host, err := authAccount(cmd, config, hostname)
+  if err != nil || host.AccessToken == "" {
+    return fmt.Errorf("no oauth token found for %s", hostname)
+  }
+
+  ui.Println(host.AccessToken)
+  return nil
+}
+
+func authSwitch(cmd *cobra.Command, args []string) error {
+  hostname := authHostname(cmd)
+  user, _ := cmd.Flags().GetString("user")
+  config := github.CurrentConfig()
+
+  accounts := config.Accounts(hostname)
+  if len(accounts) == 0 {
+    return fmt.Errorf("not logged in to %s", hostname)
+  }
+  if user == "" {
+    if len(accounts) == 1 {
+      return fmt.Errorf("%s is the only account logged in to %s", accounts[0].User, hostname)
+    }
+    if len(accounts) != 2 {
+      logins := []string{}
+      for _, h := range accounts {
+        logins = append(logins, h.User)
+      }
+      return fmt.Errorf("%s has accounts %s; use --user to pick one", hostname, strings.Join(logins, ", "))
+    }
+    for _, h := range accounts {
+      if h != config.ActiveAccount(hostname) {
+        user = h.User
+      }
+    }
+  }
+
+  if err := config.Switch(hostname, user); err != nil {
+    return err
+  }
+  if err := config.Save(); err != nil {
+    return err
+  }
+
+  active := config.ActiveAccount(hostname)
+  ui.Errorf("%s Switched active account for %s to %s\n", ui.Green("✓"), hostname, ui.Bold(active.User))
+  if override := config.RepositoryUser(); override != "" && config.Find(hostname) != active {
+    ui.Errorf("%s This repository keeps using %s as set by git config gh.user\n", ui.Yellow("!"), override)
+  }
+  return nil
+}
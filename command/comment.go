@@ -0,0 +1,225 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/npathai/github-cli-clone/utils"
+  "github.com/spf13/cobra"
+  "io/ioutil"
+  "os"
+  "sort"
+  "strings"
+  "time"
+)
+
+func addCommentBodyFlags(cmd *cobra.Command) {
+  cmd.Flags().StringP("body", "b", "", "Supply a body. Will prompt for one otherwise.")
+  cmd.Flags().StringP("body-file", "F", "", "Read body text from `file` (use \"-\" to read from standard input)")
+  cmd.Flags().BoolP("editor", "e", false, "Add body using editor")
+}
+
+// commentBody reads the text of a comment from --body or --body-file, or
+// composes it in the text editor.
+func commentBody(cmd *cobra.Command, topic string) (string, error) {
+  body, _ := cmd.Flags().GetString("body")
+  bodyFile, _ := cmd.Flags().GetString("body-file")
+  useEditor, _ := cmd.Flags().GetBool("editor")
+
+  given := 0
+  for _, set := range []bool{body != "", bodyFile != "", useEditor} {
+    if set {
+      given++
+    }
+  }
+  if given > 1 {
+    return "", fmt.Errorf("specify only one of --body, --body-file, or --editor")
+  }
+
+  if bodyFile != "" {
+    var data []byte
+    var err error
+    if bodyFile == "-" {
+      data, err = ioutil.ReadAll(os.Stdin)
+    } else {
+      data, err = ioutil.ReadFile(bodyFile)
+    }
+    if err != nil {
+      return "", err
+    }
+    body = string(data)
+  } else if body == "" {
+    if !ui.IsTerminal(os.Stdin) || !ui.IsTerminal(os.Stdout) {
+      return "", fmt.Errorf("--body or --body-file required when not running interactively")
+    }
+
+    editor, err := github.NewEditor("COMMENT_EDITMSG", topic, "")
+    if err != nil {
+      return "", err
+    }
+    editor.AddCommentedSection(fmt.Sprintf("Write a %s. Lines starting with '%s' will be ignored.", topic, editor.CS))
+    body, err = editor.EditContent()
+    editor.DeleteFile()
+    if err != nil {
+      return "", err
+    }
+  }
+
+  body = strings.TrimSpace(body)
+  if body == "" {
+    return "", fmt.Errorf("Aborting due to empty %s", topic)
+  }
+  return body, nil
+}
+
+// printComments renders comments oldest first, marking review comments with
+// the file and line they refer to.
+func printComments(comments []github.Comment) {
+  sort.SliceStable(comments, func(i, j int) bool {
+    return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+  })
+
+  for _, c := range comments {
+    printComment(c, "")
+    ui.Println("")
+  }
+}
+
+// timelineItem is an entry in the conversation of a pull request: either a
+// comment, with the replies to it when it is a review comment, or the
+// summary of a review.
+type timelineItem struct {
+  Comment *github.Comment
+  Replies []github.Comment
+  Review  *github.Review
+}
+
+func (item timelineItem) time() time.Time {
+  if item.Review != nil {
+    return item.Review.SubmittedAt
+  }
+  return item.Comment.CreatedAt
+}
+
+// prTimeline orders the comments and reviews of a pull request by time. Review
+// comments replying to another are grouped under the comment that started the
+// thread, and reviews only show up when they carry a verdict or a body, since
+// the others merely hold line comments.
+func prTimeline(comments, reviewComments []github.Comment, reviews []github.Review) []timelineItem {
+  items := []timelineItem{}
+  for i := range comments {
+    items = append(items, timelineItem{Comment: &comments[i]})
+  }
+
+  byId := map[int]*github.Comment{}
+  for i := range reviewComments {
+    byId[reviewComments[i].Id] = &reviewComments[i]
+  }
+  threadRoot := func(c *github.Comment) *github.Comment {
+    seen := map[int]bool{}
+    for c.InReplyToId != 0 && !seen[c.Id] {
+      seen[c.Id] = true
+      parent, ok := byId[c.InReplyToId]
+      if !ok {
+        break
+      }
+      c = parent
+    }
+    return c
+  }
+
+  replies := map[int][]github.Comment{}
+  for i := range reviewComments {
+    c := &reviewComments[i]
+    if root := threadRoot(c); root != c {
+      replies[root.Id] = append(replies[root.Id], *c)
+    }
+  }
+  for i := range reviewComments {
+    c := &reviewComments[i]
+    if threadRoot(c) != c {
+      continue
+    }
+    thread := replies[c.Id]
+    sort.SliceStable(thread, func(i, j int) bool {
+      return thread[i].CreatedAt.Before(thread[j].CreatedAt)
+    })
+    items = append(items, timelineItem{Comment: c, Replies: thread})
+  }
+
+  for i := range reviews {
+    r := &reviews[i]
+    if r.State == "PENDING" || (r.State == "COMMENTED" && strings.TrimSpace(r.Body) == "") {
+      continue
+    }
+    items = append(items, timelineItem{Review: r})
+  }
+
+  sort.SliceStable(items, func(i, j int) bool {
+    return items[i].time().Before(items[j].time())
+  })
+  return items
+}
+
+// printTimeline renders the conversation of a pull request, with replies
+// indented under the review comment they answer.
+func printTimeline(items []timelineItem) {
+  for _, item := range items {
+    if item.Review != nil {
+      printReview(*item.Review)
+    } else {
+      printComment(*item.Comment, "")
+      for _, reply := range item.Replies {
+        printComment(reply, "    ")
+      }
+    }
+    ui.Println("")
+  }
+}
+
+func printComment(c github.Comment, indent string) {
+  author := "ghost"
+  if c.User != nil {
+    author = c.User.Login
+  }
+
+  header := ui.Bold(author)
+  if indent != "" {
+    header = fmt.Sprintf("%s replied", header)
+  } else if c.Path != "" {
+    location := c.Path
+    if c.Line > 0 {
+      location = fmt.Sprintf("%s:%d", c.Path, c.Line)
+    }
+    header = fmt.Sprintf("%s commented on %s", header, ui.Cyan(location))
+  }
+  ui.Printf("%s%s %s\n", indent, header, ui.Gray("• "+utils.TimeAgo(c.CreatedAt)))
+  printCommentBody(c.Body, indent)
+}
+
+func printReview(r github.Review) {
+  author := "ghost"
+  if r.User != nil {
+    author = r.User.Login
+  }
+
+  verdict := "reviewed"
+  switch r.State {
+  case "APPROVED":
+    verdict = ui.Green("approved")
+  case "CHANGES_REQUESTED":
+    verdict = ui.Red("requested changes")
+  case "DISMISSED":
+    verdict = ui.Gray("reviewed (dismissed)")
+  }
+  ui.Printf("%s %s %s\n", ui.Bold(author), verdict, ui.Gray("• "+utils.TimeAgo(r.SubmittedAt)))
+  if body := strings.TrimSpace(r.Body); body != "" {
+    printCommentBody(body, "")
+  }
+}
+
+func printCommentBody(body, indent string) {
+  for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+    ui.Printf("%s  %s\n", indent, line)
+  }
+}
@@ -0,0 +1,76 @@
+package command
+
+import (
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestPrTimeline(t *testing.T) {
+  ui.SetColorEnabled(false)
+
+  start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+  at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+  user := func(login string) *github.User { return &github.User{Login: login} }
+
+  comments := []github.Comment{
+    {Id: 100, User: user("alice"), Body: "Thanks for the fix!", CreatedAt: at(1)},
+  }
+  reviewComments := []github.Comment{
+    {Id: 3, User: user("carol"), Body: "Done.", Path: "main.go", Line: 10, InReplyToId: 2, CreatedAt: at(8)},
+    {Id: 1, User: user("bob"), Body: "Rename this?", Path: "main.go", Line: 10, CreatedAt: at(2)},
+    {Id: 2, User: user("carol"), Body: "Which name?", Path: "main.go", Line: 10, InReplyToId: 1, CreatedAt: at(4)},
+    {Id: 4, User: user("bob"), Body: "Its parent is gone.", Path: "util.go", Line: 3, InReplyToId: 99, CreatedAt: at(3)},
+  }
+  reviews := []github.Review{
+    {Id: 10, User: user("bob"), State: "COMMENTED", SubmittedAt: at(2)},
+    {Id: 11, User: user("bob"), State: "CHANGES_REQUESTED", Body: "A few nits.", SubmittedAt: at(5)},
+    {Id: 12, User: user("dave"), State: "PENDING", SubmittedAt: at(6)},
+    {Id: 13, User: user("bob"), State: "APPROVED", SubmittedAt: at(9)},
+  }
+
+  timeline := prTimeline(comments, reviewComments, reviews)
+
+  got := []string{}
+  for _, item := range timeline {
+    if item.Review != nil {
+      got = append(got, "review "+item.Review.State)
+      continue
+    }
+    entry := "comment " + item.Comment.Body
+    for _, reply := range item.Replies {
+      entry += " / " + reply.Body
+    }
+    got = append(got, entry)
+  }
+  want := []string{
+    "comment Thanks for the fix!",
+    "comment Rename this? / Which name? / Done.",
+    "comment Its parent is gone.",
+    "review CHANGES_REQUESTED",
+    "review APPROVED",
+  }
+  if strings.Join(got, "\n") != strings.Join(want, "\n") {
+    t.Errorf("prTimeline() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+  }
+
+  out, _, restore := captureOutput()
+  defer restore()
+  printTimeline(timeline)
+
+  for _, line := range []string{
+    "bob commented on main.go:10 • ",
+    "  Rename this?\n    carol replied • ",
+    "      Which name?\n    carol replied • ",
+    "      Done.\n",
+    "bob requested changes • ",
+    "  A few nits.\n",
+    "bob approved • ",
+  } {
+    if !strings.Contains(out.String(), line) {
+      t.Errorf("output is missing %q:\n%s", line, out.String())
+    }
+  }
+}
@@ -0,0 +1,58 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+  "strings"
+)
+
+func init() {
+  RootCmd.AddCommand(configCmd)
+  configCmd.AddCommand(configMigrateCmd)
+
+  configMigrateCmd.Flags().String("to", "", "The `format` to convert to: yaml or toml")
+}
+
+var configCmd = &cobra.Command{
+  Use: "config",
+  Short: "Manage the configuration file",
+  Args: cobra.MinimumNArgs(1),
+}
+
+var configMigrateCmd = &cobra.Command{
+  Use: "migrate --to <format>",
+  Short: "Convert the configuration file to another format",
+  Long: `Rewrite the configuration file in YAML or TOML.
+
+The format of the file is detected from its content, so the file keeps working
+under the same name after being converted. Every host is read back after the
+conversion and the original file is restored if anything was lost.`,
+  Args: cobra.NoArgs,
+  RunE: configMigrate,
+}
+
+func configMigrate(cmd *cobra.Command, args []string) error {
+  to, _ := cmd.Flags().GetString("to")
+  if to == "" {
+    return fmt.Errorf("specify the format to convert to with --to yaml or --to toml")
+  }
+  format, err := github.ConfigFormatNamed(to)
+  if err != nil {
+    return err
+  }
+
+  filename := github.ConfigFile()
+  name := strings.ToUpper(format.Name)
+  if github.ConfigFileFormat() == format {
+    ui.Errorf("%s is already in %s format\n", filename, name)
+    return nil
+  }
+
+  if err := github.MigrateConfig(format); err != nil {
+    return err
+  }
+  ui.Errorf("%s Migrated %s to %s\n", ui.Green("✓"), filename, name)
+  return nil
+}
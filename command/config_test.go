@@ -0,0 +1,47 @@
+package command
+
+import (
+  "github.com/npathai/github-cli-clone/github"
+  "io/ioutil"
+  "net/http"
+  "strings"
+  "testing"
+)
+
+func runConfigMigrate(t *testing.T, to string) (string, error) {
+  _, errOut, restore := captureOutput()
+  defer restore()
+
+  defer setFlags(t, configMigrateCmd, map[string]string{"to": to})()
+
+  err := configMigrate(configMigrateCmd, nil)
+  return errOut.String(), err
+}
+
+func TestConfigMigrate(t *testing.T) {
+  defer stubAPI(t, "github.com:\n- user: me\n  oauth_token: token\n  protocol: https\n", http.NotFound)()
+
+  output, err := runConfigMigrate(t, "TOML")
+  if err != nil {
+    t.Fatalf("config migrate error: %s", err)
+  }
+  if !strings.Contains(output, "Migrated "+github.ConfigFile()+" to TOML") {
+    t.Errorf("output = %q", output)
+  }
+  data, _ := ioutil.ReadFile(github.ConfigFile())
+  if !strings.Contains(string(data), "[[hosts]]") || !strings.Contains(string(data), `user = "me"`) {
+    t.Errorf("configuration after migrating =\n%s", data)
+  }
+
+  output, err = runConfigMigrate(t, "toml")
+  if err != nil || !strings.Contains(output, "is already in TOML format") {
+    t.Errorf("migrating again = %q, %v", output, err)
+  }
+
+  if _, err := runConfigMigrate(t, "ini"); err == nil || !strings.Contains(err.Error(), "unknown config format") {
+    t.Errorf("migrating to ini error = %v", err)
+  }
+  if _, err := runConfigMigrate(t, ""); err == nil {
+    t.Error("migrating without --to succeeded, want an error")
+  }
+}
@@ -0,0 +1,151 @@
+package command
+
+import (
+  "bytes"
+  "fmt"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+  "io/ioutil"
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "os/exec"
+  "path/filepath"
+  "testing"
+)
+
+// stubAPI routes every API request to handler, with a temporary configuration
+// holding config and tokens set for github.com and Enterprise hosts. The
+// returned function restores the environment.
+func stubAPI(t *testing.T, config string, handler http.HandlerFunc) func() {
+  server := httptest.NewServer(handler)
+
+  dir, err := ioutil.TempDir("", "gh-command")
+  if err != nil {
+    t.Fatal(err)
+  }
+  configFile := filepath.Join(dir, "hub")
+  if err := ioutil.WriteFile(configFile, []byte(config), 0600); err != nil {
+    t.Fatal(err)
+  }
+
+  restore := setEnv(map[string]string{
+    "HUB_TEST_HOST":        server.URL,
+    "HUB_CONFIG":           configFile,
+    "HUB_CREDENTIAL_STORE": "plaintext",
+    "GH_TOKEN":             "github-token",
+    "GH_ENTERPRISE_TOKEN":  "enterprise-token",
+    "GITHUB_TOKEN":         "",
+    "GITHUB_USER":          "me",
+    "GH_HOST":              "",
+    "GITHUB_HOST":          "",
+  })
+  return func() {
+    restore()
+    server.Close()
+    os.RemoveAll(dir)
+  }
+}
+
+// setEnv sets the variables, unsetting those given as empty strings, and
+// returns a function restoring their previous values.
+func setEnv(vars map[string]string) func() {
+  previous := map[string]*string{}
+  for name, value := range vars {
+    if old, ok := os.LookupEnv(name); ok {
+      previous[name] = &old
+    } else {
+      previous[name] = nil
+    }
+    if value == "" {
+      os.Unsetenv(name)
+    } else {
+      os.Setenv(name, value)
+    }
+  }
+  return func() {
+    for name, old := range previous {
+      if old == nil {
+        os.Unsetenv(name)
+      } else {
+        os.Setenv(name, *old)
+      }
+    }
+  }
+}
+
+// useGitRepo changes into a new git repository with an initial commit on
+// master and the given remotes, and returns a function changing back. The
+// repository is always created at the same path, since the git package
+// caches the location of the repository.
+func useGitRepo(t *testing.T, remotes map[string]string) func() {
+  dir := filepath.Join(os.TempDir(), fmt.Sprintf("gh-repo-%d", os.Getpid()))
+  os.RemoveAll(dir)
+  if err := os.MkdirAll(dir, 0755); err != nil {
+    t.Fatal(err)
+  }
+  previous, err := os.Getwd()
+  if err != nil {
+    t.Fatal(err)
+  }
+  if err := os.Chdir(dir); err != nil {
+    t.Fatal(err)
+  }
+  restore := func() {
+    os.Chdir(previous)
+    os.RemoveAll(dir)
+  }
+
+  runGit(t, "init", "-q")
+  runGit(t, "config", "user.name", "Test")
+  runGit(t, "config", "user.email", "test@example.com")
+  runGit(t, "checkout", "-q", "-b", "master")
+  runGit(t, "commit", "-q", "--allow-empty", "-m", "initial")
+  for name, url := range remotes {
+    runGit(t, "remote", "add", name, url)
+  }
+  return restore
+}
+
+// runGit runs git in the current directory and returns its output.
+func runGit(t *testing.T, args ...string) string {
+  output, err := exec.Command("git", args...).CombinedOutput()
+  if err != nil {
+    t.Fatalf("git %v: %s\n%s", args, err, output)
+  }
+  return string(bytes.TrimSpace(output))
+}
+
+// captureOutput collects everything printed through ui and returns the
+// buffers along with a function restoring the previous output.
+func captureOutput() (*bytes.Buffer, *bytes.Buffer, func()) {
+  var out, errOut bytes.Buffer
+  previous, stdout, stderr := ui.Default, ui.Stdout, ui.Stderr
+  ui.Default = ui.Console{Stdout: &out, Stderr: &errOut}
+  ui.Stdout, ui.Stderr = &out, &errOut
+  return &out, &errOut, func() { ui.Default, ui.Stdout, ui.Stderr = previous, stdout, stderr }
+}
+
+// setFlags sets flags of cmd as if given on the command line and returns a
+// function restoring their defaults.
+func setFlags(t *testing.T, cmd *cobra.Command, values map[string]string) func() {
+  for name, value := range values {
+    if err := cmd.Flags().Set(name, value); err != nil {
+      t.Fatal(err)
+    }
+  }
+  return func() {
+    for name := range values {
+      f := cmd.Flags().Lookup(name)
+      if f.Value.Type() == "stringSlice" {
+        // Slice flags append once set, so swap in a fresh value.
+        fresh := &cobra.Command{}
+        fresh.Flags().StringSlice(name, nil, "")
+        f.Value = fresh.Flags().Lookup(name).Value
+      } else {
+        f.Value.Set(f.DefValue)
+      }
+      f.Changed = false
+    }
+  }
+}
@@ -0,0 +1,348 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/npathai/github-cli-clone/utils"
+  "github.com/spf13/cobra"
+  "net/url"
+  "os"
+  "regexp"
+  "strconv"
+  "strings"
+)
+
+func init() {
+  RootCmd.AddCommand(issueCmd)
+  issueCmd.AddCommand(issueListCmd)
+  issueCmd.AddCommand(issueViewCmd)
+  issueCmd.AddCommand(issueCloseCmd)
+  issueCmd.AddCommand(issueReopenCmd)
+  issueCmd.AddCommand(issueCommentCmd)
+
+  issueListCmd.Flags().IntP("limit", "L", 30, "Maximum number of issues to fetch")
+  issueListCmd.Flags().StringP("state", "s", "open", "Filter by state: {open|closed|all}")
+  issueListCmd.Flags().StringSliceP("label", "l", nil, "Filter by labels")
+  issueListCmd.Flags().StringP("assignee", "a", "", "Filter by assignee")
+  issueListCmd.Flags().StringP("author", "A", "", "Filter by author")
+  issueListCmd.Flags().String("mention", "", "Filter by mention")
+  issueListCmd.Flags().StringP("milestone", "m", "", "Filter by milestone `name`")
+
+  issueViewCmd.Flags().BoolP("web", "w", false, "Open the issue in the browser")
+  issueViewCmd.Flags().BoolP("comments", "c", false, "View issue comments")
+
+  addCommentBodyFlags(issueCommentCmd)
+}
+
+var issueCmd = &cobra.Command{
+  Use: "issue",
+  Short: "Work with issues",
+  Long: "This command allows you to work with issues",
+  Args: cobra.MinimumNArgs(1),
+}
+
+var issueListCmd = &cobra.Command{
+  Use: "list",
+  Short: "List issues",
+  Args: cobra.NoArgs,
+  RunE: issueList,
+}
+
+var issueViewCmd = &cobra.Command{
+  Use: "view {<number> | <url>}",
+  Short: "View an issue",
+  Args: cobra.ExactArgs(1),
+  RunE: issueView,
+}
+
+var issueCloseCmd = &cobra.Command{
+  Use: "close {<number> | <url>}",
+  Short: "Close an issue",
+  Args: cobra.ExactArgs(1),
+  RunE: issueClose,
+}
+
+var issueReopenCmd = &cobra.Command{
+  Use: "reopen {<number> | <url>}",
+  Short: "Reopen an issue",
+  Args: cobra.ExactArgs(1),
+  RunE: issueReopen,
+}
+
+var issueCommentCmd = &cobra.Command{
+  Use: "comment {<number> | <url>}",
+  Short: "Create a new issue comment",
+  Args: cobra.ExactArgs(1),
+  RunE: issueComment,
+}
+
+func issueList(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+  client := github.NewClient(project.Host)
+
+  limit, _ := cmd.Flags().GetInt("limit")
+  if limit < 1 {
+    return fmt.Errorf("invalid limit: %d", limit)
+  }
+  state, _ := cmd.Flags().GetString("state")
+  labels, _ := cmd.Flags().GetStringSlice("label")
+  assignee, _ := cmd.Flags().GetString("assignee")
+  author, _ := cmd.Flags().GetString("author")
+  mention, _ := cmd.Flags().GetString("mention")
+  milestone, _ := cmd.Flags().GetString("milestone")
+
+  filterParams := map[string]interface{}{}
+  switch state {
+  case "open", "closed", "all":
+    filterParams["state"] = state
+  default:
+    return fmt.Errorf("invalid state: %s", state)
+  }
+  if len(labels) > 0 {
+    filterParams["labels"] = strings.Join(labels, ",")
+  }
+  if assignee != "" {
+    filterParams["assignee"] = assignee
+  }
+  if author != "" {
+    filterParams["creator"] = author
+  }
+  if mention != "" {
+    filterParams["mentioned"] = mention
+  }
+  if milestone != "" {
+    if milestone == "*" || milestone == "none" {
+      filterParams["milestone"] = milestone
+    } else {
+      number, err := milestoneNumber(client, project, milestone)
+      if err != nil {
+        return err
+      }
+      filterParams["milestone"] = number
+    }
+  }
+
+  // The issues endpoint also returns pull requests, which are left out here.
+  issues, err := client.FetchIssues(project, filterParams, limit, func(issue *github.Issue) bool {
+    return issue.PullRequest == nil
+  })
+  if err != nil {
+    return err
+  }
+
+  if len(issues) == 0 {
+    ui.Errorf("There are no %s issues in %s\n", state, project)
+    return nil
+  }
+
+  isTTY := ui.IsTerminal(os.Stdout)
+  table := ui.NewTablePrinter(ui.Stdout, isTTY, ui.TerminalWidth(os.Stdout))
+  for _, issue := range issues {
+    number := strconv.Itoa(issue.Number)
+    if isTTY {
+      number = "#" + number
+    }
+    table.AddField(number, issueStateColorFunc(&issue))
+    table.AddField(issue.Title, nil)
+    labels := labelList(issue.Labels)
+    if isTTY && labels != "" {
+      labels = "(" + labels + ")"
+    }
+    table.AddField(labels, ui.Gray)
+    if isTTY {
+      table.AddField(utils.TimeAgo(issue.UpdatedAt), ui.Gray)
+    } else {
+      table.AddField(issue.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"), nil)
+    }
+    table.EndRow()
+  }
+
+  return table.Render()
+}
+
+func issueView(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  issue, project, client, err := issueFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+
+  web, _ := cmd.Flags().GetBool("web")
+  if web {
+    ui.Errorf("Opening %s in your browser.\n", issue.HtmlUrl)
+    return utils.OpenInBrowser(issue.HtmlUrl)
+  }
+
+  printIssuePreview(issue)
+
+  showComments, _ := cmd.Flags().GetBool("comments")
+  if !showComments {
+    return nil
+  }
+
+  comments, err := client.FetchIssueComments(project, issue.Number)
+  if err != nil {
+    return err
+  }
+  ui.Println("")
+  if len(comments) == 0 {
+    printMessage("No comments on this issue")
+    return nil
+  }
+  printHeader(pluralizeComments(len(comments)))
+  ui.Println("")
+  printComments(comments)
+  return nil
+}
+
+func printIssuePreview(issue *github.Issue) {
+  ui.Println(ui.Bold(issue.Title))
+
+  author := ""
+  if issue.User != nil {
+    author = issue.User.Login
+  }
+  state := "Open"
+  if issue.State == "closed" {
+    state = "Closed"
+  }
+  ui.Printf("%s • %s opened %s • %s\n", issueStateColorFunc(issue)(state), author,
+    utils.TimeAgo(issue.CreatedAt), pluralizeComments(issue.Comments))
+
+  if labels := labelList(issue.Labels); labels != "" {
+    ui.Printf("%s %s\n", ui.Bold("Labels:"), labels)
+  }
+  if issue.Milestone != nil {
+    ui.Printf("%s %s\n", ui.Bold("Milestone:"), issue.Milestone.Title)
+  }
+  if assignees := userList(issue.Assignees); assignees != "" {
+    ui.Printf("%s %s\n", ui.Bold("Assignees:"), assignees)
+  }
+
+  if body := strings.TrimSpace(issue.Body); body != "" {
+    ui.Println("")
+    ui.Println(body)
+  }
+  ui.Println("")
+
+  ui.Println(ui.Gray(fmt.Sprintf("View this issue on GitHub: %s", issue.HtmlUrl)))
+}
+
+func pluralizeComments(count int) string {
+  if count == 1 {
+    return "1 comment"
+  }
+  return fmt.Sprintf("%d comments", count)
+}
+
+func issueClose(cmd *cobra.Command, args []string) error {
+  return setIssueState(args, "closed")
+}
+
+func issueReopen(cmd *cobra.Command, args []string) error {
+  return setIssueState(args, "open")
+}
+
+func setIssueState(args []string, state string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  issue, project, client, err := issueFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+
+  if issue.State == state {
+    ui.Errorf("Issue #%d is already %s\n", issue.Number, state)
+    return nil
+  }
+
+  if err := client.UpdateIssue(project, issue.Number, map[string]interface{}{"state": state}); err != nil {
+    return err
+  }
+
+  verb := "Closed"
+  if state == "open" {
+    verb = "Reopened"
+  }
+  ui.Printf("%s issue #%d (%s)\n", verb, issue.Number, issue.Title)
+  return nil
+}
+
+func issueComment(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  issue, project, client, err := issueFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+
+  body, err := commentBody(cmd, "comment")
+  if err != nil {
+    return err
+  }
+
+  comment, err := client.CreateIssueComment(project, issue.Number, body)
+  if err != nil {
+    return err
+  }
+
+  ui.Println(comment.HtmlUrl)
+  return nil
+}
+
+var issueURLRegex = regexp.MustCompile(`^/([^/]+)/([^/]+)/issues/(\d+)`)
+
+// issueFromArgs resolves the issue referred to by a number or a URL. A URL
+// may point to another repository or host, so the project and client to use
+// for further requests about the issue are returned along with it.
+func issueFromArgs(client *github.Client, project *github.Project, args []string) (*github.Issue, *github.Project, *github.Client, error) {
+  arg := args[0]
+  if number, err := strconv.Atoi(strings.TrimPrefix(arg, "#")); err == nil {
+    issue, err := client.Issue(project, number)
+    return issue, project, client, err
+  }
+
+  u, err := url.Parse(arg)
+  if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+    return nil, nil, nil, fmt.Errorf("invalid issue format: %q", arg)
+  }
+  match := issueURLRegex.FindStringSubmatch(u.Path)
+  if match == nil {
+    return nil, nil, nil, fmt.Errorf("invalid issue URL: %s", arg)
+  }
+  urlProject, err := github.NewProjectFromURL(u)
+  if err != nil {
+    return nil, nil, nil, err
+  }
+  number, _ := strconv.Atoi(match[3])
+  urlClient := client
+  if !strings.EqualFold(urlProject.Host, project.Host) {
+    urlClient = github.NewClient(urlProject.Host)
+  }
+  issue, err := urlClient.Issue(urlProject, number)
+  return issue, urlProject, urlClient, err
+}
+
+func issueStateColorFunc(issue *github.Issue) func(string) string {
+  if issue.State == "open" {
+    return ui.Green
+  }
+  return ui.Red
+}
@@ -0,0 +1,133 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+  "os"
+  "strings"
+)
+
+func init() {
+  issueCmd.AddCommand(issueCreateCmd)
+
+  issueCreateCmd.Flags().StringP("title", "t", "", "Supply a title. Will prompt for one otherwise.")
+  issueCreateCmd.Flags().StringP("body", "b", "", "Supply a body. Will prompt for one otherwise.")
+  issueCreateCmd.Flags().StringSliceP("label", "l", nil, "Add labels by `name`")
+  issueCreateCmd.Flags().StringSliceP("assignee", "a", nil, "Assign people by their `login`")
+  issueCreateCmd.Flags().StringP("milestone", "m", "", "Add the issue to a milestone by `name`")
+}
+
+var issueCreateCmd = &cobra.Command{
+  Use: "create",
+  Short: "Create a new issue",
+  Long: `Create an issue on GitHub.
+
+When --title is not given, the message is composed in the text editor
+configured by $GIT_EDITOR or $EDITOR. The first block of text becomes the
+title and the rest becomes the description.
+
+When --body is not given and the repository has issue templates, the
+description starts from one of them, and its front matter prefills the title,
+labels and assignees. If there are several, you will be asked to choose one;
+when not running interactively, only a single template is used.`,
+  Args: cobra.NoArgs,
+  RunE: issueCreate,
+}
+
+func issueCreate(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+  client := github.NewClient(project.Host)
+
+  title, _ := cmd.Flags().GetString("title")
+  body, _ := cmd.Flags().GetString("body")
+  labels, _ := cmd.Flags().GetStringSlice("label")
+  assignees, _ := cmd.Flags().GetStringSlice("assignee")
+  milestone, _ := cmd.Flags().GetString("milestone")
+
+  interactive := ui.IsTerminal(os.Stdin) && ui.IsTerminal(os.Stdout)
+  if title == "" && !interactive {
+    return fmt.Errorf("--title is required when not running interactively")
+  }
+
+  var template *github.Template
+  if body == "" {
+    template, err = chooseTemplate(github.IssueTemplates, "issue", interactive)
+    if err != nil {
+      return err
+    }
+    if template != nil {
+      labels = mergeValues(labels, template.Labels)
+      assignees = mergeValues(assignees, template.Assignees)
+    }
+  }
+
+  var editor *github.Editor
+  if title == "" {
+    defaultTitle := ""
+    message := body
+    if template != nil {
+      defaultTitle = template.Title
+      message = template.Body
+    }
+    if message = strings.TrimSpace(message); message != "" || defaultTitle != "" {
+      message = defaultTitle + "\n\n" + message + "\n"
+    }
+
+    editor, err = github.NewEditor("ISSUE_EDITMSG", "issue", message)
+    if err != nil {
+      return err
+    }
+    editor.AddCommentedSection(fmt.Sprintf(`Creating an issue for %s
+
+Write a message for this issue. The first block of
+text is the title and the rest is the description.`, project))
+
+    title, body, err = editor.EditTitleAndBody()
+    if err != nil {
+      return err
+    }
+    if title == "" {
+      editor.DeleteFile()
+      return fmt.Errorf("Aborting creation due to empty issue title")
+    }
+  } else if template != nil {
+    body = strings.TrimSpace(template.Body)
+  }
+
+  params := map[string]interface{}{
+    "title": title,
+    "body":  body,
+  }
+  if len(labels) > 0 {
+    params["labels"] = labels
+  }
+  if len(assignees) > 0 {
+    params["assignees"] = assignees
+  }
+  if milestone != "" {
+    number, err := milestoneNumber(client, project, milestone)
+    if err != nil {
+      return err
+    }
+    params["milestone"] = number
+  }
+
+  issue, err := client.CreateIssue(project, params)
+  if err != nil {
+    if editor != nil {
+      ui.Errorf("The issue message was saved to %s\n", editor.File)
+    }
+    return err
+  }
+  if editor != nil {
+    editor.DeleteFile()
+  }
+
+  ui.Println(issue.HtmlUrl)
+  return nil
+}
@@ -0,0 +1,80 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "net/http"
+  "strings"
+  "testing"
+)
+
+func TestSetIssueStateUsesTheHostOfTheURL(t *testing.T) {
+  requests := []string{}
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    requests = append(requests, fmt.Sprintf("%s %s%s %s", r.Method, r.Host, r.URL.Path, r.Header.Get("Authorization")))
+    if r.Method == "PATCH" {
+      fmt.Fprint(w, `{"number": 9, "state": "closed"}`)
+      return
+    }
+    fmt.Fprint(w, `{"number": 9, "state": "open", "title": "Broken build"}`)
+  }
+  defer stubAPI(t, "ghe.example.com:\n- user: me\n  protocol: https\n", handler)()
+  defer useGitRepo(t, map[string]string{"origin": "https://github.com/octo/hello.git"})()
+  out, _, restore := captureOutput()
+  defer restore()
+
+  if err := setIssueState([]string{"https://ghe.example.com/team/svc/issues/9"}, "closed"); err != nil {
+    t.Fatalf("setIssueState() error: %s", err)
+  }
+
+  want := []string{
+    "GET ghe.example.com/api/v3/repos/team/svc/issues/9 token enterprise-token",
+    "PATCH ghe.example.com/api/v3/repos/team/svc/issues/9 token enterprise-token",
+  }
+  if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+    t.Errorf("requests =\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+  }
+  if out.String() != "Closed issue #9 (Broken build)\n" {
+    t.Errorf("output = %q", out.String())
+  }
+}
+
+func TestIssueFromArgs(t *testing.T) {
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    number := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+    fmt.Fprintf(w, `{"number": %s}`, number)
+  }
+  defer stubAPI(t, "ghe.example.com:\n- user: me\n  protocol: https\n", handler)()
+
+  base := github.NewProject("octo", "hello", "github.com")
+  client := github.NewClient(base.Host)
+
+  tests := []struct {
+    arg         string
+    wantNumber  int
+    wantProject string
+    wantHost    string
+  }{
+    {"3", 3, "octo/hello", "github.com"},
+    {"#4", 4, "octo/hello", "github.com"},
+    {"https://github.com/other/repo/issues/5", 5, "other/repo", "github.com"},
+    {"https://ghe.example.com/team/svc/issues/6", 6, "team/svc", "ghe.example.com"},
+  }
+
+  for _, tt := range tests {
+    issue, project, issueClient, err := issueFromArgs(client, base, []string{tt.arg})
+    if err != nil {
+      t.Errorf("issueFromArgs(%q) error: %s", tt.arg, err)
+      continue
+    }
+    if issue.Number != tt.wantNumber || project.String() != tt.wantProject || issueClient.Host.Host != tt.wantHost {
+      t.Errorf("issueFromArgs(%q) = #%d in %s via %s, want #%d in %s via %s", tt.arg, issue.Number, project, issueClient.Host.Host, tt.wantNumber, tt.wantProject, tt.wantHost)
+    }
+  }
+
+  for _, arg := range []string{"feature", "https://github.com/octo/hello/pull/3"} {
+    if _, _, _, err := issueFromArgs(client, base, []string{arg}); err == nil {
+      t.Errorf("issueFromArgs(%q) succeeded, want an error", arg)
+    }
+  }
+}
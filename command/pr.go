@@ -4,12 +4,26 @@ import (
   "fmt"
   "github.com/npathai/github-cli-clone/git"
   "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
   "github.com/spf13/cobra"
+  "net/url"
+  "os"
+  "regexp"
+  "strconv"
+  "strings"
 )
 
 func init() {
   RootCmd.AddCommand(prCmd)
   prCmd.AddCommand(prListCmd)
+  prCmd.AddCommand(prStatusCmd)
+
+  prListCmd.Flags().IntP("limit", "L", 30, "Maximum number of items to fetch")
+  prListCmd.Flags().StringP("state", "s", "open", "Filter by state: {open|closed|merged|all}")
+  prListCmd.Flags().StringP("base", "B", "", "Filter by base branch")
+  prListCmd.Flags().StringP("label", "l", "", "Filter by label")
+  prListCmd.Flags().StringP("assignee", "a", "", "Filter by assignee")
+  prListCmd.Flags().StringP("author", "A", "", "Filter by author")
 }
 
 var prCmd = &cobra.Command{
@@ -25,53 +39,394 @@ var prCmd = &cobra.Command{
 var prListCmd = &cobra.Command{
   Use: "list",
   Short: "List pull requests",
-  Run: func(cmd *cobra.Command, args []string) {
-    ExecutePr()
-  },
+  Args: cobra.NoArgs,
+  RunE: prList,
+}
+
+var prStatusCmd = &cobra.Command{
+  Use: "status",
+  Short: "Show status of relevant pull requests",
+  Args: cobra.NoArgs,
+  RunE: prStatus,
 }
 
-type prFilter int
+// prStatusLimit bounds the pull requests listed in each section of pr
+// status, which shows how many more there are.
+const prStatusLimit = 10
 
-const (
-createdByViewer prFilter = iota
-reviewRequested
-)
+func prStatus(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
 
-func ExecutePr() {
-  prsCreatedByViewer := pullRequests(createdByViewer)
+  client := github.NewClient(project.Host)
+  viewer, err := client.CurrentUser()
+  if err != nil {
+    return err
+  }
+
+  var currentPR *github.PullRequestStatus
+  currentBranch, branchErr := git.CurrentBranch()
+  if branchErr == nil {
+    filterParams := map[string]interface{}{"head": headForBranch(project, currentBranch), "state": "all"}
+    prs, err := client.FetchPullRequests(project, filterParams, 1, nil)
+    if err != nil {
+      return err
+    }
+    if len(prs) > 0 {
+      currentPR, err = client.FetchPullRequestStatus(project, prs[0].Number)
+      if err != nil {
+        return err
+      }
+    }
+  }
 
-  fmt.Printf("count! %d\n", len(prsCreatedByViewer))
+  // The review-requested qualifier also matches requests made to the
+  // viewer's teams.
+  createdPRs, createdTotal, err := client.SearchPullRequests(project, "is:open author:"+viewer.Login, prStatusLimit)
+  if err != nil {
+    return err
+  }
+  reviewPRs, reviewTotal, err := client.SearchPullRequests(project, "is:open review-requested:"+viewer.Login, prStatusLimit)
+  if err != nil {
+    return err
+  }
+
+  ui.Println("")
+  ui.Printf("Relevant pull requests in %s\n", project)
+  ui.Println("")
+
+  printHeader("Current branch")
+  if currentPR != nil {
+    printPrs(project, *currentPR)
+  } else if branchErr != nil {
+    printMessage("  There is no current branch")
+  } else {
+    printMessage(fmt.Sprintf("  There is no pull request associated with %s", ui.Cyan("["+currentBranch+"]")))
+  }
+  ui.Println("")
+
+  printHeader("Created by you")
+  if len(createdPRs) > 0 {
+    printPrs(project, createdPRs...)
+    printRemaining(createdTotal - len(createdPRs))
+  } else {
+    printMessage("  You have no open pull requests")
+  }
+  ui.Println("")
+
+  printHeader("Requesting a code review from you")
+  if len(reviewPRs) > 0 {
+    printPrs(project, reviewPRs...)
+    printRemaining(reviewTotal - len(reviewPRs))
+  } else {
+    printMessage("  You have no pull requests to review")
+  }
+  ui.Println("")
+
+  return nil
 }
 
-func pullRequests(filter prFilter)[]github.PullRequest {
-  project := project()
-  client := github.NewClient(project.Host)
-  currentBranch, err := git.Head()
+func printRemaining(count int) {
+  if count > 0 {
+    printMessage(fmt.Sprintf("  And %d more", count))
+  }
+}
+
+func printPrs(project *github.Project, prs ...github.PullRequestStatus) {
+  for _, status := range prs {
+    pr := status.PullRequest
+    ui.Printf("  %s  %s %s\n", prStateColorFunc(&pr)(fmt.Sprintf("#%d", pr.Number)), truncateTitle(pr.Title), ui.Cyan("["+prHeadLabel(&pr, project)+"]"))
+
+    markers := []string{}
+    if pr.Draft {
+      markers = append(markers, ui.Gray("Draft"))
+    }
+    if summary := checksSummary(status.ChecksState); summary != "" {
+      markers = append(markers, summary)
+    }
+    if decision := reviewDecision(&pr, status.Reviews); decision != "" {
+      markers = append(markers, decision)
+    }
+
+    if len(markers) > 0 {
+      ui.Printf("  - %s\n", strings.Join(markers, " - "))
+    }
+  }
+}
+
+// checksSummary describes the combined state of the commit statuses and
+// check runs of a commit, as GraphQL rolls them up.
+func checksSummary(state string) string {
+  switch state {
+  case "FAILURE", "ERROR":
+    return ui.Red("Checks failing")
+  case "PENDING", "EXPECTED":
+    return ui.Yellow("Checks pending")
+  case "SUCCESS":
+    return ui.Green("Checks passing")
+  default:
+    return ""
+  }
+}
+
+// reviewDecision summarizes the latest review of each reviewer into a single
+// decision, the same way the web interface does.
+func reviewDecision(pr *github.PullRequest, reviews []github.Review) string {
+  latest := map[string]string{}
+  for _, review := range reviews {
+    if review.User == nil {
+      continue
+    }
+    switch review.State {
+    case "APPROVED", "CHANGES_REQUESTED":
+      latest[review.User.Login] = review.State
+    case "DISMISSED":
+      delete(latest, review.User.Login)
+    }
+  }
+
+  approved := false
+  for _, state := range latest {
+    if state == "CHANGES_REQUESTED" {
+      return ui.Red("Changes requested")
+    }
+    if state == "APPROVED" {
+      approved = true
+    }
+  }
+
+  switch {
+  case approved:
+    return ui.Green("Approved")
+  case len(pr.RequestedReviewers) > 0 || len(pr.RequestedTeams) > 0:
+    return ui.Yellow("Review required")
+  default:
+    return ""
+  }
+}
+
+func truncateTitle(title string) string {
+  const maxLength = 50
+  runes := []rune(title)
+  if len(runes) > maxLength {
+    return string(runes[:maxLength-3]) + "..."
+  }
+  return title
+}
+
+func printHeader(s string) {
+  ui.Println(ui.Bold(s))
+}
+
+func printMessage(s string) {
+  ui.Println(ui.Gray(s))
+}
+
+func prList(cmd *cobra.Command, args []string) error {
+  project, err := project()
   if err != nil {
-    panic(err)
+    return err
+  }
+
+  limit, _ := cmd.Flags().GetInt("limit")
+  if limit < 1 {
+    return fmt.Errorf("invalid limit: %d", limit)
+  }
+  state, _ := cmd.Flags().GetString("state")
+  base, _ := cmd.Flags().GetString("base")
+  label, _ := cmd.Flags().GetString("label")
+  assignee, _ := cmd.Flags().GetString("assignee")
+  author, _ := cmd.Flags().GetString("author")
+
+  switch state {
+  case "open", "closed", "merged", "all":
+  default:
+    return fmt.Errorf("invalid state: %s", state)
   }
 
-  headWithOwner := fmt.Sprintf("%s:%s", project.Owner, currentBranch)
-  filterParams := map[string]interface{}{"headWithOwner": headWithOwner}
-  prs, err := client.FetchPullRequests(&project, filterParams, 10, nil)
+  client := github.NewClient(project.Host)
+  var prs []github.PullRequest
+  if label != "" || assignee != "" || author != "" || state == "merged" {
+    // The pulls endpoint can't filter by these, so they go through search.
+    qualifiers := []string{"sort:created-desc"}
+    if state != "all" {
+      qualifiers = append(qualifiers, "is:"+state)
+    }
+    for _, q := range []struct{ name, value string }{{"base", base}, {"label", label}, {"assignee", assignee}, {"author", author}} {
+      if q.value != "" {
+        qualifiers = append(qualifiers, fmt.Sprintf("%s:%q", q.name, q.value))
+      }
+    }
+    results, _, err := client.SearchPullRequests(project, strings.Join(qualifiers, " "), limit)
+    if err != nil {
+      return err
+    }
+    for _, result := range results {
+      prs = append(prs, result.PullRequest)
+    }
+  } else {
+    filterParams := map[string]interface{}{"state": state}
+    if base != "" {
+      filterParams["base"] = base
+    }
+    prs, err = client.FetchPullRequests(project, filterParams, limit, nil)
+    if err != nil {
+      return err
+    }
+  }
+
+  if len(prs) == 0 {
+    ui.Errorf("There are no %s pull requests in %s\n", state, project)
+    return nil
+  }
+
+  isTTY := ui.IsTerminal(os.Stdout)
+  table := ui.NewTablePrinter(ui.Stdout, isTTY, ui.TerminalWidth(os.Stdout))
+  for _, pr := range prs {
+    number := fmt.Sprintf("%d", pr.Number)
+    if isTTY {
+      number = "#" + number
+    }
+    table.AddField(number, prStateColorFunc(&pr))
+    table.AddField(pr.Title, nil)
+    table.AddField(prHeadLabel(&pr, project), ui.Cyan)
+    if pr.Draft {
+      table.AddField("draft", ui.Gray)
+    } else {
+      table.AddField("", nil)
+    }
+    table.EndRow()
+  }
+
+  return table.Render()
+}
+
+// prHeadLabel returns the head branch name, qualified with the owner when the
+// pull request comes from a fork.
+func prHeadLabel(pr *github.PullRequest, project *github.Project) string {
+  if pr.Head == nil {
+    return ""
+  }
+  if pr.Head.Repo != nil && pr.Head.Repo.Owner != nil && !strings.EqualFold(pr.Head.Repo.Owner.Login, project.Owner) {
+    return pr.Head.Label
+  }
+  return pr.Head.Ref
+}
+
+func prStateColorFunc(pr *github.PullRequest) func(string) string {
+  switch {
+  case pr.Draft && pr.State == "open":
+    return ui.Gray
+  case pr.State == "open":
+    return ui.Green
+  case !pr.MergedAt.IsZero():
+    return ui.Magenta
+  default:
+    return ui.Red
+  }
+}
+
+var prURLRegex = regexp.MustCompile(`^/([^/]+)/([^/]+)/pull/(\d+)`)
+
+// prFromArgs resolves the pull request referred to by a number, a URL or a
+// branch name, falling back to the pull request for the current branch. A
+// URL may point to another repository or host, so the project and client to
+// use for further requests about the pull request are returned along with it.
+func prFromArgs(client *github.Client, project *github.Project, args []string) (*github.PullRequest, *github.Project, *github.Client, error) {
+  return prFromArgsInState(client, project, args, "open")
+}
+
+// prFromArgsInState is prFromArgs looking up branches among the pull
+// requests in state, which is open, closed or all.
+func prFromArgsInState(client *github.Client, project *github.Project, args []string, state string) (*github.PullRequest, *github.Project, *github.Client, error) {
+  if len(args) == 0 || args[0] == "" {
+    branch, err := git.CurrentBranch()
+    if err != nil {
+      return nil, nil, nil, err
+    }
+    pr, err := prForBranch(client, project, branch, state)
+    return pr, project, client, err
+  }
+
+  arg := args[0]
+  if number, err := strconv.Atoi(strings.TrimPrefix(arg, "#")); err == nil {
+    pr, err := client.PullRequest(project, number)
+    return pr, project, client, err
+  }
+
+  if u, err := url.Parse(arg); err == nil && (u.Scheme == "https" || u.Scheme == "http") {
+    match := prURLRegex.FindStringSubmatch(u.Path)
+    if match == nil {
+      return nil, nil, nil, fmt.Errorf("invalid pull request URL: %s", arg)
+    }
+    urlProject, err := github.NewProjectFromURL(u)
+    if err != nil {
+      return nil, nil, nil, err
+    }
+    number, _ := strconv.Atoi(match[3])
+    urlClient := client
+    if !strings.EqualFold(urlProject.Host, project.Host) {
+      urlClient = github.NewClient(urlProject.Host)
+    }
+    pr, err := urlClient.PullRequest(urlProject, number)
+    return pr, urlProject, urlClient, err
+  }
+
+  pr, err := prForBranch(client, project, arg, state)
+  return pr, project, client, err
+}
+
+func prForBranch(client *github.Client, project *github.Project, branch, state string) (*github.PullRequest, error) {
+  filterParams := map[string]interface{}{"head": headForBranch(project, branch), "state": state}
+  prs, err := client.FetchPullRequests(project, filterParams, 1, nil)
   if err != nil {
-    panic(err)
+    return nil, err
+  }
+  if len(prs) == 0 {
+    if state == "all" {
+      return nil, fmt.Errorf("no pull requests found for branch %q", branch)
+    }
+    return nil, fmt.Errorf("no %s pull requests found for branch %q", state, branch)
+  }
+
+  // The list endpoint omits some fields, such as mergeability, so fetch the
+  // full pull request.
+  return client.PullRequest(project, prs[0].Number)
+}
+
+// headForBranch returns the "owner:branch" head filter for a local branch.
+// The owner comes from the remote the branch is pushed to, which is a fork
+// for contributors without push access, and the branch name from its
+// upstream when that differs. A branch already given as "owner:branch" is
+// used as is.
+func headForBranch(project *github.Project, branch string) string {
+  if strings.Contains(branch, ":") {
+    return branch
   }
 
-  return prs
+  owner := project.Owner
+  if headProject, err := projectForBranch(branch); err == nil {
+    owner = headProject.Owner
+  }
+  if merge, err := git.Config(fmt.Sprintf("branch.%s.merge", branch)); err == nil {
+    branch = strings.TrimPrefix(merge, "refs/heads/")
+  }
+  return fmt.Sprintf("%s:%s", owner, branch)
 }
 
-func project() github.Project {
-  remotes, error := github.Remotes()
-  if error != nil {
-    panic(error)
+func project() (*github.Project, error) {
+  remotes, err := github.Remotes()
+  if err != nil {
+    return nil, err
   }
 
   for _, remote := range remotes {
-    if project, error := remote.Project(); error == nil {
-      return *project
+    if project, err := remote.Project(); err == nil {
+      return project, nil
     }
   }
 
-  panic("Could not get the project. What is a project? I don't know, it's kind of like a git repository I think?")
-}
\ No newline at end of file
+  return nil, fmt.Errorf("Aborted: could not find any git remote pointing to a GitHub repository")
+}
@@ -0,0 +1,154 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/git"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+)
+
+func init() {
+  prCmd.AddCommand(prCheckoutCmd)
+
+  prCheckoutCmd.Flags().StringP("branch", "b", "", "Local branch name to use (default: the name of the head branch)")
+  prCheckoutCmd.Flags().BoolP("force", "f", false, "Reset the existing local branch to the latest state of the pull request")
+}
+
+var prCheckoutCmd = &cobra.Command{
+  Use: "checkout {<number> | <url>}",
+  Short: "Check out a pull request in git",
+  Long: `Fetch the head of a pull request into a local branch and check it out.
+
+Pull requests from forks that allow edits from maintainers get a remote for
+the fork so that changes can be pushed back. Other pull requests, including
+those whose fork was deleted, are fetched from the pull request ref of the
+base repository. A local branch that is behind the pull request is fast-
+forwarded, while one that has diverged from it is only reset when --force is
+given.`,
+  Args: cobra.ExactArgs(1),
+  RunE: prCheckout,
+}
+
+func prCheckout(cmd *cobra.Command, args []string) error {
+  baseProject, err := project()
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(baseProject.Host)
+  pr, baseProject, client, err := prFromArgs(client, baseProject, args)
+  if err != nil {
+    return err
+  }
+
+  branchName, _ := cmd.Flags().GetString("branch")
+  force, _ := cmd.Flags().GetBool("force")
+
+  if pr.Head == nil {
+    return fmt.Errorf("Error: pull request #%d has no head", pr.Number)
+  }
+
+  baseRemote, err := remoteForProject(baseProject)
+  if err != nil {
+    return err
+  }
+
+  // Without the repository of the head, as when the fork was deleted, the
+  // pull request can still be fetched from the base repository.
+  headRef := pr.Head.Ref
+  headAvailable := pr.Head.Repo != nil && pr.Head.Repo.Owner != nil
+  var headProject *github.Project
+  isFork := false
+  if headAvailable {
+    headProject = github.NewProject(pr.Head.Repo.Owner.Login, pr.Head.Repo.Name, baseProject.Host)
+    isFork = !headProject.SameAs(baseProject)
+  }
+
+  if branchName == "" {
+    branchName = headRef
+    if !headAvailable {
+      branchName = fmt.Sprintf("pr-%d", pr.Number)
+    } else if isFork && pr.Base != nil && pr.Base.Repo != nil && headRef == pr.Base.Repo.DefaultBranch {
+      branchName = fmt.Sprintf("%s-%s", headProject.Owner, headRef)
+    }
+  }
+
+  var startPoint, trackRemote, trackMerge string
+  switch {
+  case headAvailable && !isFork:
+    startPoint = fmt.Sprintf("refs/remotes/%s/%s", baseRemote.Name, headRef)
+    if err := git.Fetch(baseRemote.Name, fmt.Sprintf("+refs/heads/%s:%s", headRef, startPoint)); err != nil {
+      return err
+    }
+    trackRemote = baseRemote.Name
+    trackMerge = "refs/heads/" + headRef
+  case headAvailable && pr.MaintainerCanModify:
+    headRemoteName, err := headRemoteForFork(headProject, baseRemote)
+    if err != nil {
+      return err
+    }
+    startPoint = fmt.Sprintf("refs/remotes/%s/%s", headRemoteName, headRef)
+    if err := git.Fetch(headRemoteName, fmt.Sprintf("+refs/heads/%s:%s", headRef, startPoint)); err != nil {
+      return err
+    }
+    trackRemote = headRemoteName
+    trackMerge = "refs/heads/" + headRef
+  default:
+    pullRef := fmt.Sprintf("refs/pull/%d/head", pr.Number)
+    if err := git.Fetch(baseRemote.Name, pullRef); err != nil {
+      return err
+    }
+    startPoint = "FETCH_HEAD"
+    trackRemote = baseRemote.Name
+    trackMerge = pullRef
+  }
+
+  if git.HasLocalBranch(branchName) {
+    switch {
+    case git.IsAncestor(branchName, startPoint):
+      // Up to date or behind, which fast-forwards.
+      if err := git.Checkout(branchName); err != nil {
+        return err
+      }
+      if err := git.MergeFastForward(startPoint); err != nil {
+        return err
+      }
+    case git.IsAncestor(startPoint, branchName) && !force:
+      // Ahead with local commits, which are kept.
+      if err := git.Checkout(branchName); err != nil {
+        return err
+      }
+      ui.Errorf("Local branch %s has commits that are not part of the pull request\n", branchName)
+    case force:
+      if err := git.CheckoutNewBranch(branchName, startPoint, true); err != nil {
+        return err
+      }
+    default:
+      return fmt.Errorf("Aborted: local branch %q has diverged from the pull request. Use --force to reset it.", branchName)
+    }
+  } else {
+    if err := git.CheckoutNewBranch(branchName, startPoint, false); err != nil {
+      return err
+    }
+  }
+
+  if err := git.SetConfig(fmt.Sprintf("branch.%s.remote", branchName), trackRemote); err != nil {
+    return err
+  }
+  return git.SetConfig(fmt.Sprintf("branch.%s.merge", branchName), trackMerge)
+}
+
+// headRemoteForFork finds the git remote for a fork, adding one named after
+// the fork owner when none exists yet.
+func headRemoteForFork(headProject *github.Project, baseRemote *github.Remote) (string, error) {
+  if remote, err := remoteForProject(headProject); err == nil {
+    return remote.Name, nil
+  }
+
+  isSSH := baseRemote.URL != nil && baseRemote.URL.Scheme == "ssh"
+  if err := git.AddRemote(headProject.Owner, headProject.GitURL("", "", isSSH)); err != nil {
+    return "", err
+  }
+  return headProject.Owner, nil
+}
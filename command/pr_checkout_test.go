@@ -0,0 +1,122 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/git"
+  "io/ioutil"
+  "net/http"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+// useRemoteRepo creates a bare repository standing in for the GitHub
+// repositories of the remotes, with the commits of the current repository,
+// and routes the fetches of git commands run through the git package to it.
+func useRemoteRepo(t *testing.T) (string, func()) {
+  dir, err := ioutil.TempDir("", "gh-remote")
+  if err != nil {
+    t.Fatal(err)
+  }
+  bare := filepath.Join(dir, "hello.git")
+  runGit(t, "init", "-q", "--bare", bare)
+
+  previous := git.GlobalFlags
+  git.GlobalFlags = []string{"-c", fmt.Sprintf("url.%s.insteadOf=https://github.com/octo/hello.git", bare)}
+  return bare, func() {
+    git.GlobalFlags = previous
+    os.RemoveAll(dir)
+  }
+}
+
+func TestPrCheckoutOfDeletedFork(t *testing.T) {
+  defer useGitRepo(t, map[string]string{"origin": "https://github.com/octo/hello.git"})()
+  bare, done := useRemoteRepo(t)
+  defer done()
+
+  runGit(t, "commit", "-q", "--allow-empty", "-m", "first")
+  first := runGit(t, "rev-parse", "HEAD")
+  runGit(t, "commit", "-q", "--allow-empty", "-m", "second")
+  second := runGit(t, "rev-parse", "HEAD")
+  runGit(t, "reset", "-q", "--hard", "HEAD~2")
+  runGit(t, "push", "-q", bare, second+":refs/pull/5/head")
+
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    fmt.Fprintf(w, `{"number": 5, "state": "open", "head": {"ref": "feature", "sha": "%s", "repo": null}, "base": {"ref": "master"}}`, second)
+  }
+  defer stubAPI(t, "", handler)()
+  _, errOut, restore := captureOutput()
+  defer restore()
+
+  tests := []struct {
+    name      string
+    local     string
+    force     bool
+    wantHead  string
+    wantError string
+    wantWarn  string
+  }{
+    {name: "new branch", wantHead: second},
+    {name: "behind", local: first, wantHead: second},
+    {name: "ahead", local: "ahead", wantHead: "ahead", wantWarn: "has commits that are not part of the pull request"},
+    {name: "diverged", local: "diverged", wantError: "has diverged"},
+    {name: "diverged with --force", local: "diverged", force: true, wantHead: second},
+  }
+
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      runGit(t, "checkout", "-q", "master")
+      runGit(t, "update-ref", "-d", "refs/heads/pr-5")
+      errOut.Reset()
+
+      wantHead := tt.wantHead
+      switch tt.local {
+      case "":
+      case "ahead", "diverged":
+        start := second
+        if tt.local == "diverged" {
+          start = first
+        }
+        runGit(t, "checkout", "-q", "-b", "pr-5", start)
+        runGit(t, "commit", "-q", "--allow-empty", "-m", "local work")
+        if wantHead == "ahead" {
+          wantHead = runGit(t, "rev-parse", "HEAD")
+        }
+        runGit(t, "checkout", "-q", "master")
+      default:
+        runGit(t, "branch", "-q", "pr-5", tt.local)
+      }
+
+      flags := map[string]string{}
+      if tt.force {
+        flags["force"] = "true"
+      }
+      defer setFlags(t, prCheckoutCmd, flags)()
+
+      err := prCheckout(prCheckoutCmd, []string{"5"})
+      if tt.wantError != "" {
+        if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+          t.Fatalf("prCheckout() error = %v, want %q", err, tt.wantError)
+        }
+        return
+      }
+      if err != nil {
+        t.Fatalf("prCheckout() error: %s", err)
+      }
+
+      if current := runGit(t, "rev-parse", "--abbrev-ref", "HEAD"); current != "pr-5" {
+        t.Errorf("current branch = %s, want pr-5", current)
+      }
+      if head := runGit(t, "rev-parse", "HEAD"); head != wantHead {
+        t.Errorf("HEAD = %s, want %s", head, wantHead)
+      }
+      if merge := runGit(t, "config", "branch.pr-5.merge"); merge != "refs/pull/5/head" {
+        t.Errorf("branch.pr-5.merge = %s", merge)
+      }
+      if !strings.Contains(errOut.String(), tt.wantWarn) {
+        t.Errorf("warnings = %q, want %q", errOut.String(), tt.wantWarn)
+      }
+    })
+  }
+}
@@ -0,0 +1,227 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+  "os"
+  "sort"
+  "time"
+)
+
+func init() {
+  prCmd.AddCommand(prChecksCmd)
+
+  prChecksCmd.Flags().BoolP("watch", "w", false, "Watch checks until they finish")
+  prChecksCmd.Flags().IntP("interval", "i", 10, "Refresh interval in `seconds` when using --watch")
+}
+
+var prChecksCmd = &cobra.Command{
+  Use: "checks [<number> | <url> | <branch>]",
+  Short: "Show CI status for a single pull request",
+  Long: `Show commit statuses and check runs for the head commit of a pull request.
+
+The command exits with status 1 when any check failed and with status 8
+when checks are still pending.`,
+  Args: cobra.MaximumNArgs(1),
+  RunE: prChecks,
+}
+
+const (
+  checkPass = "pass"
+  checkFail = "fail"
+  checkPending = "pending"
+  checkSkipping = "skipping"
+)
+
+type checkResult struct {
+  Name    string
+  State   string
+  Elapsed time.Duration
+  Link    string
+}
+
+func prChecks(cmd *cobra.Command, args []string) error {
+  watch, _ := cmd.Flags().GetBool("watch")
+  interval, _ := cmd.Flags().GetInt("interval")
+  if interval < 1 {
+    return fmt.Errorf("invalid interval: %d", interval)
+  }
+
+  project, err := project()
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  pr, project, client, err := prFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+  if pr.Head == nil || pr.Head.Sha == "" {
+    return fmt.Errorf("no commit found for pull request #%d", pr.Number)
+  }
+
+  isTTY := ui.IsTerminal(os.Stdout)
+  for {
+    checks, err := fetchChecks(client, project, pr.Head.Sha)
+    if err != nil {
+      return err
+    }
+
+    if watch && isTTY {
+      // Move the cursor home and clear the screen so each poll redraws in place.
+      ui.Print("\033[H\033[2J")
+    }
+    if len(checks) == 0 {
+      ui.Errorf("no checks reported on the '%s' branch\n", pr.Head.Ref)
+      return nil
+    }
+    if err := printChecks(checks, isTTY); err != nil {
+      return err
+    }
+
+    counts := countChecks(checks)
+    if watch && counts[checkPending] > 0 {
+      if isTTY {
+        ui.Printf("\nRefreshing checks every %ds. Press Ctrl+C to quit.\n", interval)
+      }
+      time.Sleep(time.Duration(interval) * time.Second)
+      continue
+    }
+
+    switch {
+    case counts[checkFail] > 0:
+      return &ExitError{Code: 1}
+    case counts[checkPending] > 0:
+      return &ExitError{Code: 8}
+    default:
+      return nil
+    }
+  }
+}
+
+// fetchChecks merges legacy commit statuses and check runs into one list.
+func fetchChecks(client *github.Client, project *github.Project, sha string) ([]checkResult, error) {
+  status, err := client.FetchCombinedStatus(project, sha)
+  if err != nil {
+    return nil, err
+  }
+  checkRuns, err := client.FetchCheckRuns(project, sha)
+  if err != nil {
+    return nil, err
+  }
+
+  checks := []checkResult{}
+  for _, s := range status.Statuses {
+    state := checkFail
+    switch s.State {
+    case "success":
+      state = checkPass
+    case "pending":
+      state = checkPending
+    }
+    checks = append(checks, checkResult{
+      Name:  s.Context,
+      State: state,
+      Link:  s.TargetUrl,
+    })
+  }
+
+  now := time.Now()
+  for _, run := range checkRuns {
+    check := checkResult{Name: run.Name, Link: run.DetailsUrl}
+    if check.Link == "" {
+      check.Link = run.HtmlUrl
+    }
+
+    if run.Status != "completed" {
+      check.State = checkPending
+    } else {
+      switch run.Conclusion {
+      case "success":
+        check.State = checkPass
+      case "neutral", "skipped":
+        check.State = checkSkipping
+      default:
+        check.State = checkFail
+      }
+    }
+
+    if !run.StartedAt.IsZero() {
+      if run.CompletedAt.IsZero() {
+        check.Elapsed = now.Sub(run.StartedAt)
+      } else {
+        check.Elapsed = run.CompletedAt.Sub(run.StartedAt)
+      }
+    }
+    checks = append(checks, check)
+  }
+
+  statePriority := map[string]int{checkFail: 0, checkPending: 1, checkPass: 2, checkSkipping: 3}
+  sort.SliceStable(checks, func(i, j int) bool {
+    if checks[i].State != checks[j].State {
+      return statePriority[checks[i].State] < statePriority[checks[j].State]
+    }
+    return checks[i].Name < checks[j].Name
+  })
+  return checks, nil
+}
+
+func countChecks(checks []checkResult) map[string]int {
+  counts := map[string]int{}
+  for _, check := range checks {
+    counts[check.State]++
+  }
+  return counts
+}
+
+func printChecks(checks []checkResult, isTTY bool) error {
+  counts := countChecks(checks)
+  if isTTY {
+    summary := "All checks were successful"
+    if counts[checkFail] > 0 {
+      summary = "Some checks were not successful"
+    } else if counts[checkPending] > 0 {
+      summary = "Some checks are still pending"
+    }
+    ui.Println(ui.Bold(summary))
+    ui.Printf("%d failing, %d successful, %d skipped, and %d pending checks\n\n",
+      counts[checkFail], counts[checkPass], counts[checkSkipping], counts[checkPending])
+  }
+
+  table := ui.NewTablePrinter(ui.Stdout, isTTY, ui.TerminalWidth(os.Stdout))
+  for _, check := range checks {
+    elapsed := ""
+    if check.Elapsed > 0 {
+      elapsed = check.Elapsed.Round(time.Second).String()
+    }
+
+    if isTTY {
+      mark, colorFn := checkMark(check.State)
+      table.AddField(mark, colorFn)
+      table.AddField(check.Name, nil)
+    } else {
+      table.AddField(check.Name, nil)
+      table.AddField(check.State, nil)
+    }
+    table.AddField(elapsed, nil)
+    table.AddField(check.Link, ui.Gray)
+    table.EndRow()
+  }
+  return table.Render()
+}
+
+func checkMark(state string) (string, func(string) string) {
+  switch state {
+  case checkPass:
+    return "✓", ui.Green
+  case checkFail:
+    return "X", ui.Red
+  case checkSkipping:
+    return "-", ui.Gray
+  default:
+    return "*", ui.Yellow
+  }
+}
@@ -0,0 +1,46 @@
+package command
+
+import (
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+)
+
+func init() {
+  prCmd.AddCommand(prCommentCmd)
+
+  addCommentBodyFlags(prCommentCmd)
+}
+
+var prCommentCmd = &cobra.Command{
+  Use: "comment [<number> | <url> | <branch>]",
+  Short: "Create a new pull request comment",
+  Args: cobra.MaximumNArgs(1),
+  RunE: prComment,
+}
+
+func prComment(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  pr, project, client, err := prFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+
+  body, err := commentBody(cmd, "comment")
+  if err != nil {
+    return err
+  }
+
+  comment, err := client.CreateIssueComment(project, pr.Number, body)
+  if err != nil {
+    return err
+  }
+
+  ui.Println(comment.HtmlUrl)
+  return nil
+}
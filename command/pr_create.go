@@ -0,0 +1,338 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/git"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+  "os"
+  "strconv"
+  "strings"
+)
+
+func init() {
+  prCmd.AddCommand(prCreateCmd)
+
+  prCreateCmd.Flags().StringP("title", "t", "", "Supply a title. Will prompt for one otherwise.")
+  prCreateCmd.Flags().StringP("body", "b", "", "Supply a body. Will prompt for one otherwise.")
+  prCreateCmd.Flags().StringP("base", "B", "", "The branch into which you want your code merged")
+  prCreateCmd.Flags().StringP("head", "H", "", "The branch that contains commits for your pull request (default: current branch)")
+  prCreateCmd.Flags().BoolP("draft", "d", false, "Mark pull request as a draft")
+  prCreateCmd.Flags().BoolP("edit", "e", false, "Edit the title and body in the text editor, starting from --title and --body")
+  prCreateCmd.Flags().StringSliceP("reviewer", "r", nil, "Request reviews from people or teams by their `handle`")
+  prCreateCmd.Flags().StringSliceP("label", "l", nil, "Add labels by `name`")
+  prCreateCmd.Flags().StringSliceP("assignee", "a", nil, "Assign people by their `login`")
+  prCreateCmd.Flags().StringP("milestone", "m", "", "Add the pull request to a milestone by `name`")
+}
+
+var prCreateCmd = &cobra.Command{
+  Use: "create",
+  Short: "Create a pull request",
+  Long: `Create a pull request on GitHub.
+
+When --title is not given, or when --edit is, the message is composed in the
+text editor configured by $GIT_EDITOR or $EDITOR, starting from --title and
+--body when given. The first block of text becomes the title and the rest
+becomes the description.
+
+When --body is not given and the repository has pull request templates, the
+description starts from one of them, and its front matter adds labels and
+assignees. If there are several, you will be asked to choose one; when not
+running interactively, only a single template is used.`,
+  Args: cobra.NoArgs,
+  RunE: prCreate,
+}
+
+func prCreate(cmd *cobra.Command, args []string) error {
+  baseProject, err := project()
+  if err != nil {
+    return err
+  }
+  client := github.NewClient(baseProject.Host)
+
+  title, _ := cmd.Flags().GetString("title")
+  body, _ := cmd.Flags().GetString("body")
+  base, _ := cmd.Flags().GetString("base")
+  head, _ := cmd.Flags().GetString("head")
+  draft, _ := cmd.Flags().GetBool("draft")
+  edit, _ := cmd.Flags().GetBool("edit")
+  reviewers, _ := cmd.Flags().GetStringSlice("reviewer")
+  labels, _ := cmd.Flags().GetStringSlice("label")
+  assignees, _ := cmd.Flags().GetStringSlice("assignee")
+  milestone, _ := cmd.Flags().GetString("milestone")
+
+  if base == "" {
+    repo, err := client.Repository(baseProject)
+    if err != nil {
+      return err
+    }
+    base = repo.DefaultBranch
+  }
+
+  var headBranch string
+  if head == "" {
+    headBranch, err = git.CurrentBranch()
+    if err != nil {
+      return err
+    }
+    // The head is the upstream of the branch, which may live in a fork and
+    // go by another name.
+    head = headForBranch(baseProject, headBranch)
+    if i := strings.Index(head, ":"); i >= 0 && strings.EqualFold(head[:i], baseProject.Owner) {
+      head = head[i+1:]
+    }
+  }
+
+  fullHead := head
+  if !strings.Contains(fullHead, ":") {
+    fullHead = fmt.Sprintf("%s:%s", baseProject.Owner, head)
+  }
+  if fullHead == fmt.Sprintf("%s:%s", baseProject.Owner, base) {
+    return fmt.Errorf("Aborted: head branch is the same as base (%q)", base)
+  }
+
+  // Resolve the milestone and reviewers up front so that a typo is reported
+  // before the message is written and the pull request is created without
+  // them.
+  reviewParams, err := reviewRequestParams(baseProject, reviewers)
+  if err != nil {
+    return err
+  }
+  milestoneParam := 0
+  if milestone != "" {
+    milestoneParam, err = milestoneNumber(client, baseProject, milestone)
+    if err != nil {
+      return err
+    }
+  }
+
+  interactive := ui.IsTerminal(os.Stdin) && ui.IsTerminal(os.Stdout)
+  if (title == "" || edit) && !interactive {
+    if edit {
+      return fmt.Errorf("--edit requires running interactively")
+    }
+    return fmt.Errorf("--title is required when not running interactively")
+  }
+
+  var template *github.Template
+  if body == "" {
+    template, err = chooseTemplate(github.PullRequestTemplates, "pull request", interactive)
+    if err != nil {
+      return err
+    }
+    if template != nil {
+      labels = mergeValues(labels, template.Labels)
+      assignees = mergeValues(assignees, template.Assignees)
+    }
+  }
+
+  var editor *github.Editor
+  if title == "" || edit {
+    defaultTitle, defaultBody := defaultPrMessage(baseProject, base, headBranch)
+    if template != nil {
+      if template.Title != "" {
+        defaultTitle = template.Title
+      }
+      defaultBody = template.Body
+    }
+    if title != "" {
+      defaultTitle = title
+    }
+    if body != "" {
+      defaultBody = body
+    }
+    message := ""
+    if defaultBody = strings.TrimSpace(defaultBody); defaultBody != "" || defaultTitle != "" {
+      message = defaultTitle + "\n\n" + defaultBody + "\n"
+    }
+
+    editor, err = github.NewEditor("PULLREQ_EDITMSG", "pull request", message)
+    if err != nil {
+      return err
+    }
+    editor.AddCommentedSection(fmt.Sprintf(`Requesting a pull request to %s:%s from %s
+
+Write a message for this pull request. The first block
+of text is the title and the rest is the description.`, baseProject.Owner, base, fullHead))
+
+    title, body, err = editor.EditTitleAndBody()
+    if err != nil {
+      return err
+    }
+    if title == "" {
+      editor.DeleteFile()
+      return fmt.Errorf("Aborting due to empty pull request title")
+    }
+  } else if template != nil {
+    body = strings.TrimSpace(template.Body)
+  }
+
+  params := map[string]interface{}{
+    "title": title,
+    "body":  body,
+    "base":  base,
+    "head":  head,
+  }
+  if draft {
+    params["draft"] = true
+  }
+
+  pr, err := client.CreatePullRequest(baseProject, params)
+  if err != nil {
+    if editor != nil {
+      ui.Errorf("The pull request message was saved to %s\n", editor.File)
+    }
+    return err
+  }
+  if editor != nil {
+    editor.DeleteFile()
+  }
+
+  // Print the URL before the follow-up requests, so that a failure in one of
+  // them cannot hide that the pull request exists.
+  ui.Println(pr.HtmlUrl)
+
+  if len(reviewers) > 0 {
+    if err := client.RequestReview(baseProject, pr.Number, reviewParams); err != nil {
+      return fmt.Errorf("pull request #%d was created, but requesting reviews failed: %s", pr.Number, err)
+    }
+  }
+
+  issueParams := map[string]interface{}{}
+  if len(labels) > 0 {
+    issueParams["labels"] = labels
+  }
+  if len(assignees) > 0 {
+    issueParams["assignees"] = assignees
+  }
+  if milestoneParam > 0 {
+    issueParams["milestone"] = milestoneParam
+  }
+  if len(issueParams) > 0 {
+    if err := client.UpdateIssue(baseProject, pr.Number, issueParams); err != nil {
+      return fmt.Errorf("pull request #%d was created, but setting labels, assignees or milestone failed: %s", pr.Number, err)
+    }
+  }
+
+  return nil
+}
+
+// reviewRequestParams splits reviewer handles into users and `org/team`
+// slugs as expected by the review request endpoint. The endpoint only takes
+// the team slug, so teams must belong to the owner of the project.
+func reviewRequestParams(project *github.Project, handles []string) (map[string]interface{}, error) {
+  users := []string{}
+  teams := []string{}
+  for _, handle := range handles {
+    if i := strings.Index(handle, "/"); i >= 0 {
+      if !strings.EqualFold(handle[:i], project.Owner) {
+        return nil, fmt.Errorf("team %s can't review pull requests in %s, which belongs to %s", handle, project, project.Owner)
+      }
+      teams = append(teams, handle[i+1:])
+    } else {
+      users = append(users, handle)
+    }
+  }
+  return map[string]interface{}{
+    "reviewers":      users,
+    "team_reviewers": teams,
+  }, nil
+}
+
+func milestoneNumber(client *github.Client, project *github.Project, name string) (int, error) {
+  if number, err := strconv.Atoi(name); err == nil {
+    return number, nil
+  }
+
+  milestones, err := client.FetchMilestones(project)
+  if err != nil {
+    return 0, err
+  }
+  for _, m := range milestones {
+    if strings.EqualFold(m.Title, name) {
+      return m.Number, nil
+    }
+  }
+  return 0, fmt.Errorf("error: no milestone found with name '%s'", name)
+}
+
+// projectForBranch returns the project of the remote that the branch tracks,
+// or of `origin` when the branch has no upstream configured.
+func projectForBranch(branch string) (*github.Project, error) {
+  remoteName, err := git.Config(fmt.Sprintf("branch.%s.remote", branch))
+  if err != nil {
+    remoteName = "origin"
+  }
+
+  remote, err := remoteByName(remoteName)
+  if err != nil {
+    return nil, err
+  }
+  return remote.Project()
+}
+
+func remoteByName(name string) (*github.Remote, error) {
+  remotes, err := github.Remotes()
+  if err != nil {
+    return nil, err
+  }
+  for _, remote := range remotes {
+    if remote.Name == name {
+      return &remote, nil
+    }
+  }
+  return nil, fmt.Errorf("no git remote with name %s", name)
+}
+
+func remoteForProject(project *github.Project) (*github.Remote, error) {
+  remotes, err := github.Remotes()
+  if err != nil {
+    return nil, err
+  }
+  for _, remote := range remotes {
+    if p, err := remote.Project(); err == nil && p.SameAs(project) {
+      return &remote, nil
+    }
+  }
+  return nil, fmt.Errorf("could not find git remote for %s", project)
+}
+
+// defaultPrMessage suggests a title and body from the commits that would be
+// part of the pull request.
+func defaultPrMessage(baseProject *github.Project, base, headBranch string) (title, body string) {
+  if headBranch == "" {
+    return
+  }
+
+  baseRef := base
+  if remote, err := remoteForProject(baseProject); err == nil {
+    baseRef = fmt.Sprintf("%s/%s", remote.Name, base)
+  }
+
+  commits, err := git.RefList(baseRef, "HEAD")
+  if err != nil || len(commits) == 0 {
+    return
+  }
+
+  if len(commits) == 1 {
+    if message, err := git.Show(commits[0]); err == nil {
+      title, body = github.ReadTitleAndBody(strings.NewReader(message))
+      return
+    }
+  }
+
+  title = humanizeBranch(headBranch)
+  return
+}
+
+func humanizeBranch(branch string) string {
+  if i := strings.LastIndex(branch, "/"); i >= 0 {
+    branch = branch[i+1:]
+  }
+  title := strings.NewReplacer("-", " ", "_", " ").Replace(branch)
+  if title == "" {
+    return title
+  }
+  return strings.ToUpper(title[:1]) + title[1:]
+}
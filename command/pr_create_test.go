@@ -0,0 +1,133 @@
+package command
+
+import (
+  "encoding/json"
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "io/ioutil"
+  "net/http"
+  "os"
+  "path/filepath"
+  "reflect"
+  "strings"
+  "testing"
+)
+
+func TestReviewRequestParams(t *testing.T) {
+  project := github.NewProject("octo", "hello", "github.com")
+
+  params, err := reviewRequestParams(project, []string{"alice", "Octo/core", "octo/docs"})
+  if err != nil {
+    t.Fatalf("reviewRequestParams() error: %s", err)
+  }
+  want := map[string]interface{}{
+    "reviewers":      []string{"alice"},
+    "team_reviewers": []string{"core", "docs"},
+  }
+  if !reflect.DeepEqual(params, want) {
+    t.Errorf("reviewRequestParams() = %v, want %v", params, want)
+  }
+
+  if _, err := reviewRequestParams(project, []string{"alice", "other/core"}); err == nil || !strings.Contains(err.Error(), "other/core") {
+    t.Errorf("reviewRequestParams() with a team of another org error = %v", err)
+  }
+}
+
+func TestPrCreateUsesTheUpstreamOfTheBranch(t *testing.T) {
+  var created map[string]interface{}
+  requests := []string{}
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    requests = append(requests, r.Method+" "+r.URL.Path)
+    if r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/pulls") {
+      json.NewDecoder(r.Body).Decode(&created)
+      w.WriteHeader(http.StatusCreated)
+      fmt.Fprint(w, `{"number": 3, "html_url": "https://github.com/octo/hello/pull/3"}`)
+      return
+    }
+    http.NotFound(w, r)
+  }
+  defer stubAPI(t, "", handler)()
+  defer useGitRepo(t, map[string]string{
+    "origin": "https://github.com/octo/hello.git",
+    "fork":   "https://github.com/me/hello.git",
+  })()
+  runGit(t, "checkout", "-q", "-b", "local-name")
+  runGit(t, "config", "branch.local-name.remote", "fork")
+  runGit(t, "config", "branch.local-name.merge", "refs/heads/remote-name")
+
+  out, _, restore := captureOutput()
+  defer restore()
+  defer setFlags(t, prCreateCmd, map[string]string{"title": "Add feature", "base": "master"})()
+
+  if err := prCreate(prCreateCmd, nil); err != nil {
+    t.Fatalf("prCreate() error: %s", err)
+  }
+  if created["head"] != "me:remote-name" || created["base"] != "master" || created["title"] != "Add feature" {
+    t.Errorf("created pull request with %v, want head me:remote-name", created)
+  }
+  if out.String() != "https://github.com/octo/hello/pull/3\n" {
+    t.Errorf("output = %q", out.String())
+  }
+
+  // A team of another organization is refused before anything is created.
+  requests = requests[:0]
+  defer setFlags(t, prCreateCmd, map[string]string{"reviewer": "other/core"})()
+  if err := prCreate(prCreateCmd, nil); err == nil {
+    t.Error("prCreate() with a team of another org succeeded, want an error")
+  }
+  if len(requests) != 0 {
+    t.Errorf("requests = %q, want none", requests)
+  }
+}
+
+func TestPrCreateAppliesTheTemplateWhenTheBodyIsEmpty(t *testing.T) {
+  var created, updated map[string]interface{}
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    switch {
+    case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/pulls"):
+      created = nil
+      json.NewDecoder(r.Body).Decode(&created)
+      w.WriteHeader(http.StatusCreated)
+      fmt.Fprint(w, `{"number": 3, "html_url": "https://github.com/octo/hello/pull/3"}`)
+    case r.Method == "PATCH" && strings.HasSuffix(r.URL.Path, "/issues/3"):
+      json.NewDecoder(r.Body).Decode(&updated)
+      fmt.Fprint(w, `{"number": 3}`)
+    default:
+      http.NotFound(w, r)
+    }
+  }
+  defer stubAPI(t, "", handler)()
+  defer useGitRepo(t, map[string]string{"origin": "https://github.com/octo/hello.git"})()
+  runGit(t, "checkout", "-q", "-b", "feature")
+  template := "---\r\nlabels: needs review\r\n---\r\n## Summary\r\n\r\n"
+  if err := os.MkdirAll(".github", 0755); err != nil {
+    t.Fatal(err)
+  }
+  if err := ioutil.WriteFile(filepath.Join(".github", "pull_request_template.md"), []byte(template), 0644); err != nil {
+    t.Fatal(err)
+  }
+
+  _, _, restore := captureOutput()
+  defer restore()
+  defer setFlags(t, prCreateCmd, map[string]string{"title": "Add feature", "base": "master"})()
+
+  if err := prCreate(prCreateCmd, nil); err != nil {
+    t.Fatalf("prCreate() error: %s", err)
+  }
+  if created["body"] != "## Summary" {
+    t.Errorf("body = %q, want the template", created["body"])
+  }
+  if labels := fmt.Sprint(updated["labels"]); labels != "[needs review]" {
+    t.Errorf("labels = %s, want the ones of the template", labels)
+  }
+
+  // An explicit body leaves the template alone.
+  updated = nil
+  defer setFlags(t, prCreateCmd, map[string]string{"body": "Details"})()
+  if err := prCreate(prCreateCmd, nil); err != nil {
+    t.Fatalf("prCreate() error: %s", err)
+  }
+  if created["body"] != "Details" || updated != nil {
+    t.Errorf("created %v and updated %v, want only the given body", created, updated)
+  }
+}
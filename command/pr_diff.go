@@ -0,0 +1,119 @@
+package command
+
+import (
+  "bufio"
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+  "io"
+  "strings"
+)
+
+func init() {
+  prCmd.AddCommand(prDiffCmd)
+
+  prDiffCmd.Flags().String("color", "auto", "Use color in diff output: {always|never|auto}")
+  prDiffCmd.Flags().Bool("patch", false, "Display the changes in patch format")
+}
+
+var prDiffCmd = &cobra.Command{
+  Use: "diff [<number> | <url> | <branch>]",
+  Short: "View changes in a pull request",
+  Args: cobra.MaximumNArgs(1),
+  RunE: prDiff,
+}
+
+func prDiff(cmd *cobra.Command, args []string) error {
+  color, _ := cmd.Flags().GetString("color")
+  useColor := ui.IsColorEnabled()
+  switch color {
+  case "always":
+    useColor = true
+  case "never":
+    useColor = false
+  case "auto":
+  default:
+    return fmt.Errorf("invalid value for --color: %s", color)
+  }
+
+  ui.SetColorEnabled(useColor)
+
+  asPatch, _ := cmd.Flags().GetBool("patch")
+
+  project, err := project()
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  pr, project, client, err := prFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+
+  var diff io.ReadCloser
+  if asPatch {
+    diff, err = client.PullRequestPatch(project, pr.Number)
+  } else {
+    diff, err = client.PullRequestDiff(project, pr.Number)
+  }
+  if err != nil {
+    return err
+  }
+  defer diff.Close()
+
+  out, done, err := ui.StartPager()
+  if err != nil {
+    return err
+  }
+
+  if useColor {
+    err = colorizeDiff(diff, out)
+  } else {
+    _, err = io.Copy(out, diff)
+  }
+  if doneErr := done(); err == nil {
+    err = doneErr
+  }
+  return err
+}
+
+var diffHeaderPrefixes = []string{"diff --git ", "index ", "--- ", "+++ ", "new file mode", "deleted file mode", "similarity index", "rename from", "rename to"}
+
+func colorizeDiff(r io.Reader, w io.Writer) error {
+  reader := bufio.NewReader(r)
+  for {
+    line, readErr := reader.ReadString('\n')
+    if line != "" {
+      text := strings.TrimSuffix(line, "\n")
+      if _, err := io.WriteString(w, colorizeDiffLine(text)+line[len(text):]); err != nil {
+        return err
+      }
+    }
+    if readErr == io.EOF {
+      return nil
+    } else if readErr != nil {
+      return readErr
+    }
+  }
+}
+
+func colorizeDiffLine(line string) string {
+  for _, prefix := range diffHeaderPrefixes {
+    if strings.HasPrefix(line, prefix) {
+      return ui.Bold(line)
+    }
+  }
+
+  switch {
+  case strings.HasPrefix(line, "@@"):
+    return ui.Cyan(line)
+  case strings.HasPrefix(line, "+"):
+    return ui.Green(line)
+  case strings.HasPrefix(line, "-"):
+    return ui.Red(line)
+  default:
+    return line
+  }
+}
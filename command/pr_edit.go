@@ -0,0 +1,216 @@
+package command
+
+import (
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+  "strings"
+)
+
+func init() {
+  prCmd.AddCommand(prEditCmd)
+
+  prEditCmd.Flags().StringP("title", "t", "", "Set the new title")
+  prEditCmd.Flags().StringP("body", "b", "", "Set the new body")
+  prEditCmd.Flags().StringP("base", "B", "", "Change the base `branch` for this pull request")
+  prEditCmd.Flags().StringSlice("add-label", nil, "Add labels by `name`")
+  prEditCmd.Flags().StringSlice("remove-label", nil, "Remove labels by `name`")
+  prEditCmd.Flags().StringSlice("add-assignee", nil, "Add assigned users by their `login`")
+  prEditCmd.Flags().StringSlice("remove-assignee", nil, "Remove assigned users by their `login`")
+  prEditCmd.Flags().StringSlice("add-reviewer", nil, "Add reviewers by their `login` or `org/team` slug")
+  prEditCmd.Flags().StringSlice("remove-reviewer", nil, "Remove reviewers by their `login` or `org/team` slug")
+  prEditCmd.Flags().StringP("milestone", "m", "", "Set the milestone by `name` (pass \"\" to clear it)")
+}
+
+var prEditCmd = &cobra.Command{
+  Use: "edit [<number> | <url> | <branch>]",
+  Short: "Edit a pull request",
+  Long: `Edit the title, body, base branch, labels, assignees, reviewers or milestone
+of a pull request. Only the fields that actually change are sent to GitHub.`,
+  Args: cobra.MaximumNArgs(1),
+  RunE: prEdit,
+}
+
+func prEdit(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  pr, project, client, err := prFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+
+  flags := cmd.Flags()
+  changed := []string{}
+
+  prParams := map[string]interface{}{}
+  if title, _ := flags.GetString("title"); flags.Changed("title") && title != pr.Title {
+    prParams["title"] = title
+    changed = append(changed, "title")
+  }
+  if body, _ := flags.GetString("body"); flags.Changed("body") && body != pr.Body {
+    prParams["body"] = body
+    changed = append(changed, "body")
+  }
+  if base, _ := flags.GetString("base"); flags.Changed("base") && (pr.Base == nil || base != pr.Base.Ref) {
+    prParams["base"] = base
+    changed = append(changed, "base")
+  }
+
+  issueParams := map[string]interface{}{}
+  addLabels, _ := flags.GetStringSlice("add-label")
+  removeLabels, _ := flags.GetStringSlice("remove-label")
+  currentLabels := make([]string, 0, len(pr.Labels))
+  for _, l := range pr.Labels {
+    currentLabels = append(currentLabels, l.Name)
+  }
+  if labels, ok := applyEdits(currentLabels, addLabels, removeLabels); ok {
+    issueParams["labels"] = labels
+    changed = append(changed, "labels")
+  }
+
+  addAssignees, _ := flags.GetStringSlice("add-assignee")
+  removeAssignees, _ := flags.GetStringSlice("remove-assignee")
+  currentAssignees := make([]string, 0, len(pr.Assignees))
+  for _, u := range pr.Assignees {
+    currentAssignees = append(currentAssignees, u.Login)
+  }
+  if assignees, ok := applyEdits(currentAssignees, addAssignees, removeAssignees); ok {
+    issueParams["assignees"] = assignees
+    changed = append(changed, "assignees")
+  }
+
+  if flags.Changed("milestone") {
+    milestone, _ := flags.GetString("milestone")
+    if milestone == "" {
+      if pr.Milestone != nil {
+        issueParams["milestone"] = nil
+        changed = append(changed, "milestone")
+      }
+    } else {
+      number, err := milestoneNumber(client, project, milestone)
+      if err != nil {
+        return err
+      }
+      if pr.Milestone == nil || pr.Milestone.Number != number {
+        issueParams["milestone"] = number
+        changed = append(changed, "milestone")
+      }
+    }
+  }
+
+  addReviewers, _ := flags.GetStringSlice("add-reviewer")
+  removeReviewers, _ := flags.GetStringSlice("remove-reviewer")
+  reviewersToAdd, reviewersToRemove := reviewerEdits(pr, addReviewers, removeReviewers)
+  if len(reviewersToAdd) > 0 || len(reviewersToRemove) > 0 {
+    changed = append(changed, "reviewers")
+  }
+
+  if len(changed) == 0 {
+    ui.Errorf("Nothing to update for pull request #%d\n", pr.Number)
+    return nil
+  }
+
+  addReviewParams, err := reviewRequestParams(project, reviewersToAdd)
+  if err != nil {
+    return err
+  }
+  removeReviewParams, err := reviewRequestParams(project, reviewersToRemove)
+  if err != nil {
+    return err
+  }
+
+  if len(prParams) > 0 {
+    if _, err := client.UpdatePullRequest(project, pr.Number, prParams); err != nil {
+      return err
+    }
+  }
+  if len(issueParams) > 0 {
+    if err := client.UpdateIssue(project, pr.Number, issueParams); err != nil {
+      return err
+    }
+  }
+  if len(reviewersToAdd) > 0 {
+    if err := client.RequestReview(project, pr.Number, addReviewParams); err != nil {
+      return err
+    }
+  }
+  if len(reviewersToRemove) > 0 {
+    if err := client.RemoveReviewRequest(project, pr.Number, removeReviewParams); err != nil {
+      return err
+    }
+  }
+
+  ui.Printf("Updated %s of pull request #%d\n", strings.Join(changed, ", "), pr.Number)
+  ui.Println(pr.HtmlUrl)
+  return nil
+}
+
+// applyEdits adds and removes names from a list, ignoring case, and reports
+// whether the resulting list differs from the original. A name that is both
+// added and removed ends up removed.
+func applyEdits(current, add, remove []string) ([]string, bool) {
+  result := []string{}
+  changed := false
+  for _, name := range current {
+    if containsFold(remove, name) {
+      changed = true
+      continue
+    }
+    result = append(result, name)
+  }
+  for _, name := range add {
+    if !containsFold(result, name) && !containsFold(remove, name) {
+      result = append(result, name)
+      changed = true
+    }
+  }
+  return result, changed
+}
+
+// reviewerEdits drops review requests that already exist from the additions
+// and ones that don't exist from the removals. As with applyEdits, a handle
+// that is both added and removed ends up removed.
+func reviewerEdits(pr *github.PullRequest, add, remove []string) (toAdd, toRemove []string) {
+  isRequested := func(handle string) bool {
+    if i := strings.Index(handle, "/"); i >= 0 {
+      slug := handle[i+1:]
+      for _, t := range pr.RequestedTeams {
+        if strings.EqualFold(t.Slug, slug) {
+          return true
+        }
+      }
+      return false
+    }
+    for _, u := range pr.RequestedReviewers {
+      if strings.EqualFold(u.Login, handle) {
+        return true
+      }
+    }
+    return false
+  }
+
+  for _, handle := range add {
+    if !isRequested(handle) && !containsFold(remove, handle) && !containsFold(toAdd, handle) {
+      toAdd = append(toAdd, handle)
+    }
+  }
+  for _, handle := range remove {
+    if isRequested(handle) && !containsFold(toRemove, handle) {
+      toRemove = append(toRemove, handle)
+    }
+  }
+  return
+}
+
+func containsFold(list []string, s string) bool {
+  for _, item := range list {
+    if strings.EqualFold(item, s) {
+      return true
+    }
+  }
+  return false
+}
@@ -0,0 +1,69 @@
+package command
+
+import (
+  "github.com/npathai/github-cli-clone/github"
+  "reflect"
+  "testing"
+)
+
+func TestApplyEdits(t *testing.T) {
+  tests := []struct {
+    name        string
+    current     []string
+    add         []string
+    remove      []string
+    want        []string
+    wantChanged bool
+  }{
+    {"nothing", []string{"bug"}, nil, nil, []string{"bug"}, false},
+    {"add", []string{"bug"}, []string{"docs"}, nil, []string{"bug", "docs"}, true},
+    {"add existing", []string{"bug"}, []string{"BUG"}, nil, []string{"bug"}, false},
+    {"add twice", nil, []string{"docs", "Docs"}, nil, []string{"docs"}, true},
+    {"remove", []string{"bug", "docs"}, nil, []string{"Bug"}, []string{"docs"}, true},
+    {"remove missing", []string{"bug"}, nil, []string{"docs"}, []string{"bug"}, false},
+    {"add and remove existing", []string{"bug"}, []string{"bug"}, []string{"bug"}, []string{}, true},
+    {"add and remove missing", []string{"bug"}, []string{"docs"}, []string{"docs"}, []string{"bug"}, false},
+  }
+
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      got, changed := applyEdits(tt.current, tt.add, tt.remove)
+      if !reflect.DeepEqual(got, tt.want) || changed != tt.wantChanged {
+        t.Errorf("applyEdits() = %q, %v, want %q, %v", got, changed, tt.want, tt.wantChanged)
+      }
+    })
+  }
+}
+
+func TestReviewerEdits(t *testing.T) {
+  pr := &github.PullRequest{
+    RequestedReviewers: []github.User{{Login: "mona"}},
+    RequestedTeams:     []github.Team{{Slug: "core"}},
+  }
+
+  tests := []struct {
+    name       string
+    add        []string
+    remove     []string
+    wantAdd    []string
+    wantRemove []string
+  }{
+    {name: "add", add: []string{"hubot", "Mona"}, wantAdd: []string{"hubot"}},
+    {name: "add twice", add: []string{"hubot", "HUBOT"}, wantAdd: []string{"hubot"}},
+    {name: "remove", remove: []string{"MONA", "hubot"}, wantRemove: []string{"MONA"}},
+    {name: "add and remove requested", add: []string{"mona"}, remove: []string{"mona"}, wantRemove: []string{"mona"}},
+    {name: "add and remove unrequested", add: []string{"hubot"}, remove: []string{"hubot"}},
+    {name: "add team", add: []string{"octo/docs", "octo/Core"}, wantAdd: []string{"octo/docs"}},
+    {name: "remove team", remove: []string{"octo/core", "octo/docs"}, wantRemove: []string{"octo/core"}},
+    {name: "user named like a team", add: []string{"core"}, remove: []string{"octo/mona"}, wantAdd: []string{"core"}},
+  }
+
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      toAdd, toRemove := reviewerEdits(pr, tt.add, tt.remove)
+      if !reflect.DeepEqual(toAdd, tt.wantAdd) || !reflect.DeepEqual(toRemove, tt.wantRemove) {
+        t.Errorf("reviewerEdits() = %q, %q, want %q, %q", toAdd, toRemove, tt.wantAdd, tt.wantRemove)
+      }
+    })
+  }
+}
@@ -0,0 +1,201 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/git"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+  "sort"
+)
+
+func init() {
+  prCmd.AddCommand(prMergeCmd)
+
+  prMergeCmd.Flags().Bool("merge", false, "Merge the commits with the base branch")
+  prMergeCmd.Flags().Bool("squash", false, "Squash the commits into one commit and merge it into the base branch")
+  prMergeCmd.Flags().Bool("rebase", false, "Rebase the commits onto the base branch")
+  prMergeCmd.Flags().StringP("subject", "t", "", "Subject text for the merge commit")
+  prMergeCmd.Flags().StringP("body", "b", "", "Body text for the merge commit")
+  prMergeCmd.Flags().String("sha", "", "Only merge if the head of the pull request matches this commit `SHA`")
+  prMergeCmd.Flags().BoolP("delete-branch", "d", false, "Delete the local and remote branch after merge")
+}
+
+var prMergeCmd = &cobra.Command{
+  Use: "merge [<number> | <url> | <branch>]",
+  Short: "Merge a pull request",
+  Long: `Merge a pull request on GitHub.
+
+Without an argument, the pull request that belongs to the current branch
+is merged.`,
+  Args: cobra.MaximumNArgs(1),
+  RunE: prMerge,
+}
+
+func prMerge(cmd *cobra.Command, args []string) error {
+  method, err := prMergeMethod(cmd)
+  if err != nil {
+    return err
+  }
+
+  project, err := project()
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  pr, project, client, err := prFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+
+  subject, _ := cmd.Flags().GetString("subject")
+  body, _ := cmd.Flags().GetString("body")
+  sha, _ := cmd.Flags().GetString("sha")
+  deleteBranch, _ := cmd.Flags().GetBool("delete-branch")
+
+  if !pr.MergedAt.IsZero() {
+    return fmt.Errorf("Pull request #%d was already merged", pr.Number)
+  } else if pr.State != "open" {
+    return fmt.Errorf("Pull request #%d is closed", pr.Number)
+  }
+
+  params := map[string]interface{}{"merge_method": method}
+  if subject != "" {
+    params["commit_title"] = subject
+  }
+  if body != "" {
+    params["commit_message"] = body
+  }
+  if sha != "" {
+    params["sha"] = sha
+  }
+
+  if _, err := client.MergePullRequest(project, pr.Number, params); err != nil {
+    return err
+  }
+  ui.Printf("%s pull request #%d (%s)\n", prMergeVerb(method), pr.Number, pr.Title)
+
+  if !deleteBranch {
+    return nil
+  }
+  deletePrBranches(client, project, pr)
+  return nil
+}
+
+func prMergeMethod(cmd *cobra.Command) (string, error) {
+  method := ""
+  for _, m := range []string{"merge", "squash", "rebase"} {
+    if enabled, _ := cmd.Flags().GetBool(m); enabled {
+      if method != "" {
+        return "", fmt.Errorf("only one of --merge, --squash or --rebase may be given")
+      }
+      method = m
+    }
+  }
+  if method == "" {
+    method = "merge"
+  }
+  return method, nil
+}
+
+func prMergeVerb(method string) string {
+  switch method {
+  case "squash":
+    return "Squashed and merged"
+  case "rebase":
+    return "Rebased and merged"
+  default:
+    return "Merged"
+  }
+}
+
+// deletePrBranches deletes the head branch of a pull request on GitHub,
+// including a branch of a fork that the viewer may push to, along with the
+// local branches tracking it. A checked out branch is first switched to the
+// base branch. Local branches holding commits that are not part of the pull
+// request are kept, and failures are only reported as warnings since the
+// pull request itself was handled already.
+func deletePrBranches(client *github.Client, project *github.Project, pr *github.PullRequest) {
+  if pr.Head == nil {
+    return
+  }
+
+  var headProject *github.Project
+  if repo := pr.Head.Repo; repo == nil || repo.Owner == nil {
+    ui.Errorf("Skipped deleting remote branch %s: its repository no longer exists\n", pr.Head.Ref)
+  } else {
+    headProject = github.NewProject(repo.Owner.Login, repo.Name, project.Host)
+    permissions := repo.Permissions
+    if permissions == nil && !headProject.SameAs(project) {
+      // The repository of a pull request does not tell whether the viewer
+      // may push to it.
+      if headRepo, err := client.Repository(headProject); err == nil {
+        permissions = headRepo.Permissions
+      }
+    }
+
+    if permissions != nil && !permissions.Push {
+      ui.Errorf("Skipped deleting remote branch %s: you lack push access to %s\n", pr.Head.Ref, headProject)
+    } else if err := client.DeleteBranch(headProject, pr.Head.Ref); err != nil {
+      ui.Errorf("%s Failed to delete remote branch %s of %s: %s\n", ui.Yellow("!"), pr.Head.Ref, headProject, err)
+    } else {
+      ui.Printf("Deleted remote branch %s of %s\n", pr.Head.Ref, headProject)
+    }
+  }
+
+  currentBranch, _ := git.CurrentBranch()
+  for _, branch := range localPrBranches(project, headProject, pr) {
+    if branch == currentBranch {
+      if pr.Base == nil {
+        continue
+      }
+      if err := git.Checkout(pr.Base.Ref); err != nil {
+        ui.Errorf("%s Failed to switch to %s: %s\n", ui.Yellow("!"), pr.Base.Ref, err)
+        continue
+      }
+      ui.Printf("Switched to %s\n", pr.Base.Ref)
+    }
+
+    err := git.DeleteLocalBranch(branch, false)
+    if err != nil && pr.Head.Sha != "" && git.IsAncestor(branch, pr.Head.Sha) {
+      // Squashed or rebased pull requests leave the branch unmerged, which
+      // is safe to delete as long as it holds nothing beyond the head.
+      err = git.DeleteLocalBranch(branch, true)
+    }
+    if err != nil {
+      ui.Errorf("%s Kept local branch %s: %s\n", ui.Yellow("!"), branch, err)
+      continue
+    }
+    ui.Printf("Deleted local branch %s\n", branch)
+  }
+}
+
+// localPrBranches returns the local branches whose upstream is the head
+// branch of the pull request, or its pull request ref on the base project.
+func localPrBranches(project, headProject *github.Project, pr *github.PullRequest) []string {
+  upstreams, err := git.BranchUpstreams()
+  if err != nil {
+    return nil
+  }
+
+  branches := []string{}
+  for branch, upstream := range upstreams {
+    remote, err := remoteByName(upstream.Remote)
+    if err != nil {
+      continue
+    }
+    remoteProject, err := remote.Project()
+    if err != nil {
+      continue
+    }
+
+    tracksHead := headProject != nil && remoteProject.SameAs(headProject) && upstream.Merge == "refs/heads/"+pr.Head.Ref
+    tracksPullRef := remoteProject.SameAs(project) && upstream.Merge == fmt.Sprintf("refs/pull/%d/head", pr.Number)
+    if tracksHead || tracksPullRef {
+      branches = append(branches, branch)
+    }
+  }
+  sort.Strings(branches)
+  return branches
+}
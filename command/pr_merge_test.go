@@ -0,0 +1,104 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "net/http"
+  "strings"
+  "testing"
+)
+
+func TestDeletePrBranches(t *testing.T) {
+  requests := []string{}
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    requests = append(requests, r.Method+" "+r.URL.Path)
+    switch {
+    case r.Method == "GET" && r.URL.Path == "/repos/me/hello":
+      fmt.Fprint(w, `{"name": "hello", "owner": {"login": "me"}, "permissions": {"push": true}}`)
+    case r.Method == "DELETE":
+      w.WriteHeader(http.StatusNoContent)
+    default:
+      http.NotFound(w, r)
+    }
+  }
+  defer stubAPI(t, "", handler)()
+  defer useGitRepo(t, map[string]string{
+    "origin": "https://github.com/octo/hello.git",
+    "fork":   "https://github.com/me/hello.git",
+  })()
+
+  // master holds the initial commit and the pull request one more.
+  runGit(t, "checkout", "-q", "-b", "feature")
+  runGit(t, "commit", "-q", "--allow-empty", "-m", "feature")
+  headSha := runGit(t, "rev-parse", "HEAD")
+  runGit(t, "branch", "-q", "from-pull-ref")
+  runGit(t, "branch", "-q", "with-local-work")
+  runGit(t, "branch", "-q", "unrelated")
+  runGit(t, "checkout", "-q", "with-local-work")
+  runGit(t, "commit", "-q", "--allow-empty", "-m", "not pushed")
+  runGit(t, "checkout", "-q", "feature")
+
+  for branch, upstream := range map[string][2]string{
+    "feature":         {"fork", "refs/heads/feature"},
+    "from-pull-ref":   {"origin", "refs/pull/5/head"},
+    "with-local-work": {"fork", "refs/heads/feature"},
+    "unrelated":       {"origin", "refs/heads/feature"},
+  } {
+    runGit(t, "config", "branch."+branch+".remote", upstream[0])
+    runGit(t, "config", "branch."+branch+".merge", upstream[1])
+  }
+
+  out, errOut, restore := captureOutput()
+  defer restore()
+
+  pr := &github.PullRequest{
+    Number: 5,
+    Head: &github.PullRequestSpec{Ref: "feature", Sha: headSha, Repo: &github.Repository{Name: "hello", Owner: &github.User{Login: "me"}}},
+    Base: &github.PullRequestSpec{Ref: "master"},
+  }
+  deletePrBranches(github.NewClient("github.com"), github.NewProject("octo", "hello", "github.com"), pr)
+
+  if want := "GET /repos/me/hello\nDELETE /repos/me/hello/git/refs/heads/feature"; strings.Join(requests, "\n") != want {
+    t.Errorf("requests =\n%s\nwant\n%s", strings.Join(requests, "\n"), want)
+  }
+  if current := runGit(t, "rev-parse", "--abbrev-ref", "HEAD"); current != "master" {
+    t.Errorf("current branch = %s, want master", current)
+  }
+  if branches := runGit(t, "branch", "--format=%(refname:short)"); branches != "master\nunrelated\nwith-local-work" {
+    t.Errorf("remaining branches =\n%s", branches)
+  }
+  if !strings.Contains(errOut.String(), "Kept local branch with-local-work") {
+    t.Errorf("warnings = %q, want with-local-work kept", errOut.String())
+  }
+  for _, line := range []string{"Deleted remote branch feature of me/hello", "Switched to master", "Deleted local branch feature", "Deleted local branch from-pull-ref"} {
+    if !strings.Contains(out.String(), line) {
+      t.Errorf("output is missing %q:\n%s", line, out.String())
+    }
+  }
+}
+
+func TestDeletePrBranchesWithoutPushAccess(t *testing.T) {
+  requests := []string{}
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    requests = append(requests, r.Method+" "+r.URL.Path)
+    fmt.Fprint(w, `{"name": "hello", "owner": {"login": "someone"}, "permissions": {"push": false}}`)
+  }
+  defer stubAPI(t, "", handler)()
+  defer useGitRepo(t, map[string]string{"origin": "https://github.com/octo/hello.git"})()
+  _, errOut, restore := captureOutput()
+  defer restore()
+
+  pr := &github.PullRequest{
+    Number: 6,
+    Head: &github.PullRequestSpec{Ref: "patch", Repo: &github.Repository{Name: "hello", Owner: &github.User{Login: "someone"}}},
+    Base: &github.PullRequestSpec{Ref: "master"},
+  }
+  deletePrBranches(github.NewClient("github.com"), github.NewProject("octo", "hello", "github.com"), pr)
+
+  if len(requests) != 1 || requests[0] != "GET /repos/someone/hello" {
+    t.Errorf("requests = %q, want only the permission lookup", requests)
+  }
+  if !strings.Contains(errOut.String(), "you lack push access to someone/hello") {
+    t.Errorf("warnings = %q", errOut.String())
+  }
+}
@@ -0,0 +1,154 @@
+package command
+
+import (
+  "encoding/json"
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+  "io/ioutil"
+  "os"
+)
+
+func init() {
+  prCmd.AddCommand(prReviewCmd)
+
+  prReviewCmd.Flags().BoolP("approve", "a", false, "Approve pull request")
+  prReviewCmd.Flags().BoolP("request-changes", "r", false, "Request changes on a pull request")
+  prReviewCmd.Flags().BoolP("comment", "c", false, "Comment on a pull request")
+  prReviewCmd.Flags().StringP("body", "b", "", "Specify the body of a review")
+  prReviewCmd.Flags().String("comments-file", "", "Read inline comments from a JSON `file` (use \"-\" for standard input)")
+}
+
+var prReviewCmd = &cobra.Command{
+  Use: "review [<number> | <url> | <branch>]",
+  Short: "Add a review to a pull request",
+  Long: `Approve, request changes on, or comment on a pull request.
+
+With --comments-file, inline comments are read from a JSON array of objects
+with "path", "line", "side" ("LEFT" or "RIGHT") and "body" keys, and are
+submitted together as a single review. Multi-line comments may also set
+"start_line" and "start_side".`,
+  Args: cobra.MaximumNArgs(1),
+  RunE: prReview,
+}
+
+func prReview(cmd *cobra.Command, args []string) error {
+  body, _ := cmd.Flags().GetString("body")
+  commentsFile, _ := cmd.Flags().GetString("comments-file")
+
+  var comments []github.ReviewComment
+  if commentsFile != "" {
+    var err error
+    comments, err = readReviewComments(commentsFile)
+    if err != nil {
+      return err
+    }
+  }
+
+  event, err := prReviewEvent(cmd, len(comments) > 0)
+  if err != nil {
+    return err
+  }
+  if event == "REQUEST_CHANGES" && body == "" {
+    return fmt.Errorf("body cannot be blank for request-changes review")
+  }
+  if event == "COMMENT" && body == "" && len(comments) == 0 {
+    return fmt.Errorf("body cannot be blank for comment review")
+  }
+
+  project, err := project()
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  pr, project, client, err := prFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+
+  params := map[string]interface{}{"event": event}
+  if body != "" {
+    params["body"] = body
+  }
+  if pr.Head != nil && pr.Head.Sha != "" {
+    // Pin line numbers to the commit that was reviewed.
+    params["commit_id"] = pr.Head.Sha
+  }
+  if len(comments) > 0 {
+    params["comments"] = comments
+  }
+
+  if _, err := client.CreatePullRequestReview(project, pr.Number, params); err != nil {
+    return err
+  }
+
+  switch event {
+  case "APPROVE":
+    ui.Printf("Approved pull request #%d\n", pr.Number)
+  case "REQUEST_CHANGES":
+    ui.Printf("Requested changes to pull request #%d\n", pr.Number)
+  default:
+    if len(comments) > 0 {
+      ui.Printf("Reviewed pull request #%d with %d inline comments\n", pr.Number, len(comments))
+    } else {
+      ui.Printf("Reviewed pull request #%d\n", pr.Number)
+    }
+  }
+  return nil
+}
+
+func prReviewEvent(cmd *cobra.Command, hasComments bool) (string, error) {
+  events := map[string]string{
+    "approve":         "APPROVE",
+    "request-changes": "REQUEST_CHANGES",
+    "comment":         "COMMENT",
+  }
+
+  event := ""
+  for _, flag := range []string{"approve", "request-changes", "comment"} {
+    if enabled, _ := cmd.Flags().GetBool(flag); enabled {
+      if event != "" {
+        return "", fmt.Errorf("need exactly one of --approve, --request-changes, or --comment")
+      }
+      event = events[flag]
+    }
+  }
+
+  if event == "" {
+    if !hasComments {
+      return "", fmt.Errorf("need exactly one of --approve, --request-changes, or --comment")
+    }
+    event = "COMMENT"
+  }
+  return event, nil
+}
+
+func readReviewComments(filename string) ([]github.ReviewComment, error) {
+  var data []byte
+  var err error
+  if filename == "-" {
+    data, err = ioutil.ReadAll(os.Stdin)
+  } else {
+    data, err = ioutil.ReadFile(filename)
+  }
+  if err != nil {
+    return nil, err
+  }
+
+  comments := []github.ReviewComment{}
+  if err := json.Unmarshal(data, &comments); err != nil {
+    return nil, fmt.Errorf("error parsing %s: %s", filename, err)
+  }
+
+  for i, c := range comments {
+    if c.Path == "" || c.Line < 1 || c.Body == "" {
+      return nil, fmt.Errorf("error parsing %s: comment %d needs a path, a line and a body", filename, i+1)
+    }
+    if c.Side != "" && c.Side != "LEFT" && c.Side != "RIGHT" {
+      return nil, fmt.Errorf("error parsing %s: comment %d has invalid side %q", filename, i+1, c.Side)
+    }
+  }
+  return comments, nil
+}
@@ -0,0 +1,141 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+)
+
+func init() {
+  prCmd.AddCommand(prCloseCmd)
+  prCmd.AddCommand(prReopenCmd)
+  prCmd.AddCommand(prReadyCmd)
+
+  prCloseCmd.Flags().BoolP("delete-branch", "d", false, "Delete the local and remote branch after close")
+  prReadyCmd.Flags().Bool("undo", false, "Convert a pull request back to draft")
+}
+
+var prCloseCmd = &cobra.Command{
+  Use: "close {<number> | <url> | <branch>}",
+  Short: "Close a pull request",
+  Args: cobra.ExactArgs(1),
+  RunE: prClose,
+}
+
+var prReopenCmd = &cobra.Command{
+  Use: "reopen {<number> | <url> | <branch>}",
+  Short: "Reopen a pull request",
+  Args: cobra.ExactArgs(1),
+  RunE: prReopen,
+}
+
+var prReadyCmd = &cobra.Command{
+  Use: "ready [<number> | <url> | <branch>]",
+  Short: "Mark a pull request as ready for review",
+  Long: `Mark a draft pull request as ready for review.
+
+With --undo, convert a pull request that is ready for review back to draft.`,
+  Args: cobra.MaximumNArgs(1),
+  RunE: prReady,
+}
+
+func prClose(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  pr, project, client, err := prFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+
+  if !pr.MergedAt.IsZero() {
+    return fmt.Errorf("Pull request #%d can't be closed because it was already merged", pr.Number)
+  } else if pr.State == "closed" {
+    ui.Errorf("Pull request #%d is already closed\n", pr.Number)
+    return nil
+  }
+
+  if _, err := client.UpdatePullRequest(project, pr.Number, map[string]interface{}{"state": "closed"}); err != nil {
+    return err
+  }
+  ui.Printf("Closed pull request #%d (%s)\n", pr.Number, pr.Title)
+
+  deleteBranch, _ := cmd.Flags().GetBool("delete-branch")
+  if !deleteBranch {
+    return nil
+  }
+  deletePrBranches(client, project, pr)
+  return nil
+}
+
+func prReopen(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+
+  // The pull request of a branch is looked up among closed ones, since an
+  // open one has nothing to reopen.
+  client := github.NewClient(project.Host)
+  pr, project, client, err := prFromArgsInState(client, project, args, "closed")
+  if err != nil {
+    return err
+  }
+
+  if !pr.MergedAt.IsZero() {
+    return fmt.Errorf("Pull request #%d can't be reopened because it was already merged", pr.Number)
+  } else if pr.State == "open" {
+    ui.Errorf("Pull request #%d is already open\n", pr.Number)
+    return nil
+  }
+
+  if _, err := client.UpdatePullRequest(project, pr.Number, map[string]interface{}{"state": "open"}); err != nil {
+    return err
+  }
+  ui.Printf("Reopened pull request #%d (%s)\n", pr.Number, pr.Title)
+  return nil
+}
+
+func prReady(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  pr, project, client, err := prFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+
+  if pr.State != "open" {
+    return fmt.Errorf("Pull request #%d is closed. Only draft pull requests can be marked as \"ready for review\"", pr.Number)
+  }
+
+  undo, _ := cmd.Flags().GetBool("undo")
+  if undo {
+    if pr.Draft {
+      ui.Errorf("Pull request #%d is already a draft\n", pr.Number)
+      return nil
+    }
+    if err := client.ConvertPullRequestToDraft(pr); err != nil {
+      return err
+    }
+    ui.Printf("Pull request #%d is converted to draft\n", pr.Number)
+    return nil
+  }
+
+  if !pr.Draft {
+    ui.Errorf("Pull request #%d is already \"ready for review\"\n", pr.Number)
+    return nil
+  }
+  if err := client.MarkPullRequestReady(pr); err != nil {
+    return err
+  }
+  ui.Printf("Pull request #%d is marked as \"ready for review\"\n", pr.Number)
+  return nil
+}
@@ -0,0 +1,333 @@
+package command
+
+import (
+  "encoding/json"
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "io/ioutil"
+  "net/http"
+  "reflect"
+  "strings"
+  "testing"
+)
+
+func TestChecksSummary(t *testing.T) {
+  ui.SetColorEnabled(false)
+
+  tests := []struct {
+    state string
+    want  string
+  }{
+    {"", ""},
+    {"SUCCESS", "Checks passing"},
+    {"PENDING", "Checks pending"},
+    {"EXPECTED", "Checks pending"},
+    {"FAILURE", "Checks failing"},
+    {"ERROR", "Checks failing"},
+  }
+
+  for _, tt := range tests {
+    if got := checksSummary(tt.state); got != tt.want {
+      t.Errorf("checksSummary(%q) = %q, want %q", tt.state, got, tt.want)
+    }
+  }
+}
+
+func TestReviewDecision(t *testing.T) {
+  ui.SetColorEnabled(false)
+
+  review := func(login, state string) github.Review {
+    return github.Review{User: &github.User{Login: login}, State: state}
+  }
+  requested := &github.PullRequest{RequestedReviewers: []github.User{{Login: "carol"}}}
+
+  tests := []struct {
+    name    string
+    pr      *github.PullRequest
+    reviews []github.Review
+    want    string
+  }{
+    {"no reviews", &github.PullRequest{}, nil, ""},
+    {"awaiting review", requested, nil, "Review required"},
+    {"approved", requested, []github.Review{review("alice", "APPROVED")}, "Approved"},
+    {"changes requested by anyone", &github.PullRequest{}, []github.Review{review("alice", "APPROVED"), review("bob", "CHANGES_REQUESTED")}, "Changes requested"},
+    {"latest review counts", &github.PullRequest{}, []github.Review{review("bob", "CHANGES_REQUESTED"), review("bob", "APPROVED")}, "Approved"},
+    {"dismissed review", requested, []github.Review{review("bob", "CHANGES_REQUESTED"), review("bob", "DISMISSED")}, "Review required"},
+  }
+
+  for _, tt := range tests {
+    if got := reviewDecision(tt.pr, tt.reviews); got != tt.want {
+      t.Errorf("%s: reviewDecision() = %q, want %q", tt.name, got, tt.want)
+    }
+  }
+}
+
+func TestPrFromArgs(t *testing.T) {
+  requests := []string{}
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    requests = append(requests, fmt.Sprintf("%s %s%s %s", r.Method, r.Host, r.URL.RequestURI(), r.Header.Get("Authorization")))
+    switch {
+    case strings.HasSuffix(r.URL.Path, "/user"):
+      fmt.Fprint(w, `{"login": "me"}`)
+      return
+    case strings.HasSuffix(r.URL.Path, "/pulls"):
+      fmt.Fprint(w, `[{"number": 7}]`)
+    case strings.Contains(r.URL.Path, "/pulls/"):
+      number := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+      fmt.Fprintf(w, `{"number": %s, "state": "open"}`, number)
+    default:
+      http.NotFound(w, r)
+    }
+  }
+  defer stubAPI(t, "ghe.example.com:\n- user: me\n  protocol: https\n", handler)()
+
+  base := github.NewProject("octo", "hello", "github.com")
+
+  tests := []struct {
+    name        string
+    args        []string
+    wantNumber  int
+    wantProject string
+    wantHost    string
+    wantRequest string
+  }{
+    {"number", []string{"12"}, 12, "octo/hello", "github.com", "GET api.github.com/repos/octo/hello/pulls/12 token github-token"},
+    {"hash number", []string{"#13"}, 13, "octo/hello", "github.com", "GET api.github.com/repos/octo/hello/pulls/13 token github-token"},
+    {"URL of another repository", []string{"https://github.com/other/repo/pull/14/files"}, 14, "other/repo", "github.com", "GET api.github.com/repos/other/repo/pulls/14 token github-token"},
+    {"URL on another host", []string{"https://ghe.example.com/team/svc/pull/15"}, 15, "team/svc", "ghe.example.com", "GET ghe.example.com/api/v3/repos/team/svc/pulls/15 token enterprise-token"},
+    {"qualified branch", []string{"fork:feature"}, 7, "octo/hello", "github.com", "GET api.github.com/repos/octo/hello/pulls?per_page=1&head=fork%3Afeature&state=open token github-token"},
+  }
+
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      requests = requests[:0]
+      pr, project, client, err := prFromArgs(github.NewClient(base.Host), base, tt.args)
+      if err != nil {
+        t.Fatalf("prFromArgs() error: %s", err)
+      }
+      if pr.Number != tt.wantNumber {
+        t.Errorf("pr.Number = %d, want %d", pr.Number, tt.wantNumber)
+      }
+      if project.String() != tt.wantProject || project.Host != tt.wantHost {
+        t.Errorf("project = %s on %s, want %s on %s", project, project.Host, tt.wantProject, tt.wantHost)
+      }
+      if client.Host.Host != tt.wantHost {
+        t.Errorf("client host = %s, want %s", client.Host.Host, tt.wantHost)
+      }
+      if len(requests) == 0 || requests[0] != tt.wantRequest {
+        t.Errorf("requests = %q, want first %q", requests, tt.wantRequest)
+      }
+    })
+  }
+}
+
+func TestPrFromArgsRejectsOtherURLs(t *testing.T) {
+  defer stubAPI(t, "", http.NotFound)()
+
+  base := github.NewProject("octo", "hello", "github.com")
+  _, _, _, err := prFromArgs(github.NewClient(base.Host), base, []string{"https://github.com/octo/hello/issues/3"})
+  if err == nil || !strings.Contains(err.Error(), "invalid pull request URL") {
+    t.Errorf("prFromArgs() error = %v, want an invalid URL error", err)
+  }
+}
+
+func TestPrFromArgsInStateLooksUpClosedBranches(t *testing.T) {
+  var query string
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    if strings.HasSuffix(r.URL.Path, "/pulls") {
+      query = r.URL.RawQuery
+      fmt.Fprint(w, `[{"number": 4}]`)
+      return
+    }
+    fmt.Fprint(w, `{"number": 4, "state": "closed"}`)
+  }
+  defer stubAPI(t, "", handler)()
+
+  base := github.NewProject("octo", "hello", "github.com")
+  pr, _, _, err := prFromArgsInState(github.NewClient(base.Host), base, []string{"octo:feature"}, "closed")
+  if err != nil {
+    t.Fatalf("prFromArgsInState() error: %s", err)
+  }
+  if pr.Number != 4 || !strings.Contains(query, "state=closed") {
+    t.Errorf("got #%d with query %q, want #4 looked up with state=closed", pr.Number, query)
+  }
+}
+
+func TestPrCloseLooksUpOpenBranches(t *testing.T) {
+  var query, update string
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    switch {
+    case strings.HasSuffix(r.URL.Path, "/pulls"):
+      query = r.URL.RawQuery
+      fmt.Fprint(w, `[{"number": 5}]`)
+    case r.Method == "PATCH":
+      body, _ := ioutil.ReadAll(r.Body)
+      update = r.URL.Path + " " + string(body)
+      fmt.Fprint(w, `{"number": 5, "state": "closed"}`)
+    default:
+      fmt.Fprint(w, `{"number": 5, "state": "open", "title": "Add feature"}`)
+    }
+  }
+  defer stubAPI(t, "", handler)()
+  defer useGitRepo(t, map[string]string{"origin": "https://github.com/octo/hello.git"})()
+  out, _, restore := captureOutput()
+  defer restore()
+
+  if err := prClose(prCloseCmd, []string{"feature"}); err != nil {
+    t.Fatalf("prClose() error: %s", err)
+  }
+  if !strings.Contains(query, "head=octo%3Afeature") || !strings.Contains(query, "state=open") {
+    t.Errorf("looked up the branch with %q, want its open pull request", query)
+  }
+  if !strings.Contains(update, "/repos/octo/hello/pulls/5") || !strings.Contains(update, `"state":"closed"`) {
+    t.Errorf("update = %q, want #5 closed", update)
+  }
+  if want := "Closed pull request #5 (Add feature)\n"; out.String() != want {
+    t.Errorf("output = %q, want %q", out.String(), want)
+  }
+}
+
+func TestPrStatusTakesOneRequestPerSection(t *testing.T) {
+  ui.SetColorEnabled(false)
+
+  node := func(number int, extra string) string {
+    return fmt.Sprintf(`{"number": %d, "title": "PR %d", "state": "OPEN", "headRefName": "topic-%d",
+      "headRepositoryOwner": {"login": "octo"}, %s}`, number, number, number, extra)
+  }
+  requests := []string{}
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    requests = append(requests, r.Method+" "+r.URL.Path)
+    switch {
+    case strings.HasSuffix(r.URL.Path, "/user"):
+      fmt.Fprint(w, `{"login": "me"}`)
+    case strings.HasSuffix(r.URL.Path, "/pulls"):
+      fmt.Fprint(w, `[{"number": 1}]`)
+    case strings.HasSuffix(r.URL.Path, "/graphql"):
+      var body struct {
+        Variables map[string]interface{} `json:"variables"`
+      }
+      json.NewDecoder(r.Body).Decode(&body)
+      switch query, _ := body.Variables["query"].(string); {
+      case strings.Contains(query, "author:me"):
+        fmt.Fprintf(w, `{"data": {"search": {"issueCount": 12, "nodes": [%s, %s]}}}`,
+          node(2, `"isDraft": true, "commits": {"nodes": [{"commit": {"statusCheckRollup": {"state": "FAILURE"}}}]}`),
+          node(3, `"reviews": {"nodes": [{"state": "APPROVED", "author": {"login": "mona"}}]}`))
+      case strings.Contains(query, "review-requested:me"):
+        fmt.Fprint(w, `{"data": {"search": {"issueCount": 0, "nodes": []}}}`)
+      default:
+        fmt.Fprintf(w, `{"data": {"repository": {"pullRequest": %s}}}`,
+          node(1, `"reviewRequests": {"nodes": [{"requestedReviewer": {"slug": "core"}}]}`))
+      }
+    default:
+      http.NotFound(w, r)
+    }
+  }
+  defer stubAPI(t, "", handler)()
+  defer useGitRepo(t, map[string]string{"origin": "https://github.com/octo/hello.git"})()
+  runGit(t, "checkout", "-q", "-b", "topic-1")
+
+  out, _, restore := captureOutput()
+  defer restore()
+  if err := prStatus(prStatusCmd, nil); err != nil {
+    t.Fatalf("prStatus() error: %s", err)
+  }
+
+  wantRequests := []string{"GET /user", "GET /repos/octo/hello/pulls", "POST /graphql", "POST /graphql", "POST /graphql"}
+  if !reflect.DeepEqual(requests, wantRequests) {
+    t.Errorf("requests = %q, want %q", requests, wantRequests)
+  }
+  for _, want := range []string{
+    "#1  PR 1 [topic-1]\n  - Review required\n",
+    "#2  PR 2 [topic-2]\n  - Draft - Checks failing\n",
+    "#3  PR 3 [topic-3]\n  - Approved\n",
+    "And 10 more",
+    "You have no pull requests to review",
+  } {
+    if !strings.Contains(out.String(), want) {
+      t.Errorf("output lacks %q:\n%s", want, out.String())
+    }
+  }
+}
+
+func TestPrListFiltersOnTheServer(t *testing.T) {
+  var requests, queries []string
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    requests = append(requests, r.Method+" "+r.URL.RequestURI())
+    switch {
+    case strings.HasSuffix(r.URL.Path, "/graphql"):
+      var body struct {
+        Variables map[string]interface{} `json:"variables"`
+      }
+      json.NewDecoder(r.Body).Decode(&body)
+      queries = append(queries, fmt.Sprint(body.Variables["query"], " limit ", body.Variables["limit"], " after ", body.Variables["after"]))
+      if body.Variables["after"] == nil {
+        fmt.Fprint(w, `{"data": {"search": {"issueCount": 3, "pageInfo": {"hasNextPage": true, "endCursor": "c1"},
+          "nodes": [{"number": 9, "title": "Fix", "state": "OPEN", "headRefName": "fix"}]}}}`)
+      } else {
+        fmt.Fprint(w, `{"data": {"search": {"issueCount": 3, "pageInfo": {"hasNextPage": false},
+          "nodes": [{"number": 8, "title": "Docs", "state": "OPEN", "headRefName": "docs"}]}}}`)
+      }
+    case strings.HasSuffix(r.URL.Path, "/pulls"):
+      fmt.Fprint(w, `[{"number": 7, "title": "Feature", "head": {"ref": "feature"}}]`)
+    default:
+      http.NotFound(w, r)
+    }
+  }
+  defer stubAPI(t, "", handler)()
+  defer useGitRepo(t, map[string]string{"origin": "https://github.com/octo/hello.git"})()
+
+  tests := []struct {
+    name         string
+    flags        map[string]string
+    wantRequests []string
+    wantQueries  []string
+    wantOutput   string
+  }{
+    {
+      name:         "no filters",
+      flags:        map[string]string{"base": "main"},
+      wantRequests: []string{"GET /repos/octo/hello/pulls?per_page=45&base=main&state=open"},
+      wantOutput:   "7\tFeature\tfeature\t\n",
+    },
+    {
+      name:         "filters",
+      flags:        map[string]string{"label": "help wanted", "assignee": "mona", "author": "hubot", "base": "main", "limit": "2"},
+      wantRequests: []string{"POST /graphql", "POST /graphql"},
+      wantQueries: []string{
+        `repo:octo/hello is:pr sort:created-desc is:open base:"main" label:"help wanted" assignee:"mona" author:"hubot" limit 2 after <nil>`,
+        `repo:octo/hello is:pr sort:created-desc is:open base:"main" label:"help wanted" assignee:"mona" author:"hubot" limit 1 after c1`,
+      },
+      wantOutput: "9\tFix\tfix\t\n8\tDocs\tdocs\t\n",
+    },
+    {
+      name:         "merged",
+      flags:        map[string]string{"state": "merged", "limit": "1"},
+      wantRequests: []string{"POST /graphql"},
+      wantQueries:  []string{`repo:octo/hello is:pr sort:created-desc is:merged limit 1 after <nil>`},
+      wantOutput:   "9\tFix\tfix\t\n",
+    },
+  }
+
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      requests, queries = nil, nil
+      out, _, restore := captureOutput()
+      defer restore()
+      defer setFlags(t, prListCmd, tt.flags)()
+
+      if err := prList(prListCmd, nil); err != nil {
+        t.Fatalf("prList() error: %s", err)
+      }
+      if !reflect.DeepEqual(requests, tt.wantRequests) {
+        t.Errorf("requests = %q, want %q", requests, tt.wantRequests)
+      }
+      if !reflect.DeepEqual(queries, tt.wantQueries) {
+        t.Errorf("queries = %q, want %q", queries, tt.wantQueries)
+      }
+      if out.String() != tt.wantOutput {
+        t.Errorf("output = %q, want %q", out.String(), tt.wantOutput)
+      }
+    })
+  }
+}
@@ -0,0 +1,217 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/npathai/github-cli-clone/utils"
+  "github.com/spf13/cobra"
+  "strings"
+)
+
+func init() {
+  prCmd.AddCommand(prViewCmd)
+
+  prViewCmd.Flags().BoolP("web", "w", false, "Open the pull request in the browser")
+  prViewCmd.Flags().BoolP("comments", "c", false, "View pull request comments and reviews")
+}
+
+var prViewCmd = &cobra.Command{
+  Use: "view [<number> | <url> | <branch>]",
+  Short: "View a pull request",
+  Long: `Display the title, body, and other information about a pull request.
+
+Without an argument, the pull request that belongs to the current branch
+is displayed.`,
+  Args: cobra.MaximumNArgs(1),
+  RunE: prView,
+}
+
+func prView(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  pr, project, client, err := prFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+
+  web, _ := cmd.Flags().GetBool("web")
+  if web {
+    ui.Errorf("Opening %s in your browser.\n", pr.HtmlUrl)
+    return utils.OpenInBrowser(pr.HtmlUrl)
+  }
+
+  if err := printPrPreview(client, project, pr); err != nil {
+    return err
+  }
+
+  showComments, _ := cmd.Flags().GetBool("comments")
+  if !showComments {
+    return nil
+  }
+
+  comments, err := client.FetchIssueComments(project, pr.Number)
+  if err != nil {
+    return err
+  }
+  reviewComments, err := client.FetchReviewComments(project, pr.Number)
+  if err != nil {
+    return err
+  }
+  reviews, err := client.FetchPullRequestReviews(project, pr.Number)
+  if err != nil {
+    return err
+  }
+
+  ui.Println("")
+  timeline := prTimeline(comments, reviewComments, reviews)
+  if len(timeline) == 0 {
+    printMessage("No comments on this pull request")
+    return nil
+  }
+  header := pluralizeComments(len(comments) + len(reviewComments))
+  shownReviews := 0
+  for _, item := range timeline {
+    if item.Review != nil {
+      shownReviews++
+    }
+  }
+  if shownReviews == 1 {
+    header += ", 1 review"
+  } else if shownReviews > 1 {
+    header += fmt.Sprintf(", %d reviews", shownReviews)
+  }
+  printHeader(header)
+  ui.Println("")
+  printTimeline(timeline)
+  return nil
+}
+
+func printPrPreview(client *github.Client, project *github.Project, pr *github.PullRequest) error {
+  ui.Println(ui.Bold(pr.Title))
+
+  author := ""
+  if pr.User != nil {
+    author = pr.User.Login
+  }
+  base, head := "", prHeadLabel(pr, project)
+  if pr.Base != nil {
+    base = pr.Base.Ref
+  }
+  ui.Printf("%s • %s wants to merge into %s from %s\n",
+    prStateColorFunc(pr)(prStateTitle(pr)), author, ui.Cyan(base), ui.Cyan(head))
+
+  if labels := labelList(pr.Labels); labels != "" {
+    ui.Printf("%s %s\n", ui.Bold("Labels:"), labels)
+  }
+  if pr.Milestone != nil {
+    ui.Printf("%s %s\n", ui.Bold("Milestone:"), pr.Milestone.Title)
+  }
+  if assignees := userList(pr.Assignees); assignees != "" {
+    ui.Printf("%s %s\n", ui.Bold("Assignees:"), assignees)
+  }
+
+  reviews, err := client.FetchPullRequestReviews(project, pr.Number)
+  if err != nil {
+    return err
+  }
+  if reviewers := prReviewerList(pr, reviews); reviewers != "" {
+    ui.Printf("%s %s\n", ui.Bold("Reviewers:"), reviewers)
+  }
+
+  if body := strings.TrimSpace(pr.Body); body != "" {
+    ui.Println("")
+    ui.Println(body)
+  }
+  ui.Println("")
+
+  ui.Println(ui.Gray(fmt.Sprintf("View this pull request on GitHub: %s", pr.HtmlUrl)))
+  return nil
+}
+
+func prStateTitle(pr *github.PullRequest) string {
+  switch {
+  case pr.Draft && pr.State == "open":
+    return "Draft"
+  case pr.State == "open":
+    return "Open"
+  case !pr.MergedAt.IsZero():
+    return "Merged"
+  default:
+    return "Closed"
+  }
+}
+
+func labelList(labels []github.IssueLabel) string {
+  names := make([]string, 0, len(labels))
+  for _, l := range labels {
+    names = append(names, l.Name)
+  }
+  return strings.Join(names, ", ")
+}
+
+func userList(users []github.User) string {
+  logins := make([]string, 0, len(users))
+  for _, u := range users {
+    logins = append(logins, u.Login)
+  }
+  return strings.Join(logins, ", ")
+}
+
+// prReviewerList lists everyone who reviewed or was asked to review, along
+// with the state of their latest review.
+func prReviewerList(pr *github.PullRequest, reviews []github.Review) string {
+  order := []string{}
+  states := map[string]string{}
+  for _, review := range reviews {
+    if review.User == nil || review.State == "PENDING" {
+      continue
+    }
+    login := review.User.Login
+    if _, seen := states[login]; !seen {
+      order = append(order, login)
+    }
+    if review.State != "COMMENTED" || states[login] == "" {
+      states[login] = review.State
+    }
+  }
+  for _, u := range pr.RequestedReviewers {
+    if _, seen := states[u.Login]; !seen {
+      order = append(order, u.Login)
+    }
+    states[u.Login] = "REQUESTED"
+  }
+  for _, t := range pr.RequestedTeams {
+    name := t.Slug
+    if t.Organization != nil {
+      name = t.Organization.Login + "/" + t.Slug
+    }
+    order = append(order, name)
+    states[name] = "REQUESTED"
+  }
+
+  reviewers := make([]string, 0, len(order))
+  for _, name := range order {
+    reviewers = append(reviewers, fmt.Sprintf("%s (%s)", name, reviewStateTitle(states[name])))
+  }
+  return strings.Join(reviewers, ", ")
+}
+
+func reviewStateTitle(state string) string {
+  switch state {
+  case "APPROVED":
+    return ui.Green("Approved")
+  case "CHANGES_REQUESTED":
+    return ui.Red("Changes requested")
+  case "COMMENTED":
+    return "Commented"
+  case "DISMISSED":
+    return ui.Gray("Dismissed")
+  default:
+    return ui.Yellow("Requested")
+  }
+}
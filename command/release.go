@@ -0,0 +1,394 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/npathai/github-cli-clone/utils"
+  "github.com/spf13/cobra"
+  "io"
+  "os"
+  "path/filepath"
+  "strings"
+)
+
+func init() {
+  RootCmd.AddCommand(releaseCmd)
+  releaseCmd.AddCommand(releaseListCmd)
+  releaseCmd.AddCommand(releaseViewCmd)
+  releaseCmd.AddCommand(releaseDownloadCmd)
+  releaseCmd.AddCommand(releaseUploadCmd)
+  releaseCmd.AddCommand(releaseDeleteCmd)
+
+  releaseListCmd.Flags().IntP("limit", "L", 30, "Maximum number of releases to fetch")
+
+  releaseViewCmd.Flags().BoolP("web", "w", false, "Open the release in the browser")
+
+  releaseDownloadCmd.Flags().StringSliceP("pattern", "p", nil, "Download only assets that match a glob `pattern`")
+  releaseDownloadCmd.Flags().StringP("dir", "D", ".", "The `directory` to download files into")
+  releaseDownloadCmd.Flags().Bool("clobber", false, "Overwrite existing files")
+
+  releaseUploadCmd.Flags().Bool("clobber", false, "Replace existing assets of the same name")
+}
+
+var releaseCmd = &cobra.Command{
+  Use: "release",
+  Short: "Manage GitHub releases",
+  Long: "This command allows you to work with releases",
+  Args: cobra.MinimumNArgs(1),
+}
+
+var releaseListCmd = &cobra.Command{
+  Use: "list",
+  Short: "List releases in a repository",
+  Args: cobra.NoArgs,
+  RunE: releaseList,
+}
+
+var releaseViewCmd = &cobra.Command{
+  Use: "view [<tag>]",
+  Short: "View information about a release",
+  Long: `View information about a release.
+
+Without a tag, the latest published release is shown.`,
+  Args: cobra.MaximumNArgs(1),
+  RunE: releaseView,
+}
+
+var releaseDownloadCmd = &cobra.Command{
+  Use: "download [<tag>]",
+  Short: "Download release assets",
+  Long: `Download the assets of a release.
+
+Without a tag, the assets of the latest published release are downloaded.
+Without --pattern, all assets are downloaded.`,
+  Args: cobra.MaximumNArgs(1),
+  RunE: releaseDownload,
+}
+
+var releaseUploadCmd = &cobra.Command{
+  Use: "upload <tag> <files>...",
+  Short: "Upload assets to a release",
+  Long: `Upload files as assets of an existing release.
+
+An asset can be given a display label by appending it after '#', as in
+"dist/app.tar.gz#Linux build". Uploading a file named like an existing asset
+fails unless --clobber is given to replace it.`,
+  Args: cobra.MinimumNArgs(2),
+  RunE: releaseUpload,
+}
+
+var releaseDeleteCmd = &cobra.Command{
+  Use: "delete <tag>",
+  Short: "Delete a release",
+  Long: `Delete a release. The git tag of the release is kept.`,
+  Args: cobra.ExactArgs(1),
+  RunE: releaseDelete,
+}
+
+func releaseList(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+  client := github.NewClient(project.Host)
+
+  limit, _ := cmd.Flags().GetInt("limit")
+  if limit < 1 {
+    return fmt.Errorf("invalid limit: %d", limit)
+  }
+
+  releases, err := client.FetchReleases(project, limit, nil)
+  if err != nil {
+    return err
+  }
+
+  if len(releases) == 0 {
+    ui.Errorf("There are no releases in %s\n", project)
+    return nil
+  }
+
+  isTTY := ui.IsTerminal(os.Stdout)
+  table := ui.NewTablePrinter(ui.Stdout, isTTY, ui.TerminalWidth(os.Stdout))
+  seenLatest := false
+  for _, release := range releases {
+    title := release.Name
+    if title == "" {
+      title = release.TagName
+    }
+    table.AddField(title, nil)
+
+    badge, badgeColor := "", ui.Green
+    switch {
+    case release.Draft:
+      badge, badgeColor = "Draft", ui.Red
+    case release.Prerelease:
+      badge, badgeColor = "Pre-release", ui.Yellow
+    case !seenLatest:
+      badge = "Latest"
+      seenLatest = true
+    }
+    table.AddField(badge, badgeColor)
+
+    table.AddField(release.TagName, ui.Cyan)
+
+    date := release.PublishedAt
+    if release.Draft {
+      date = release.CreatedAt
+    }
+    if isTTY {
+      table.AddField(utils.TimeAgo(date), ui.Gray)
+    } else {
+      table.AddField(date.Format("2006-01-02T15:04:05Z07:00"), nil)
+    }
+    table.EndRow()
+  }
+
+  return table.Render()
+}
+
+// releaseFromArgs returns the release for the tag given as argument, or the
+// latest release when there is none.
+func releaseFromArgs(client *github.Client, project *github.Project, args []string) (*github.Release, error) {
+  if len(args) > 0 {
+    return client.FetchRelease(project, args[0])
+  }
+  return client.LatestRelease(project)
+}
+
+func releaseView(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+  client := github.NewClient(project.Host)
+
+  release, err := releaseFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+
+  web, _ := cmd.Flags().GetBool("web")
+  if web {
+    ui.Errorf("Opening %s in your browser.\n", release.HtmlUrl)
+    return utils.OpenInBrowser(release.HtmlUrl)
+  }
+
+  title := release.Name
+  if title == "" {
+    title = release.TagName
+  }
+  ui.Println(ui.Bold(title))
+
+  author := ""
+  if release.Author != nil {
+    author = release.Author.Login
+  }
+  switch {
+  case release.Draft:
+    ui.Printf("%s • %s created this %s\n", ui.Red("Draft"), author, utils.TimeAgo(release.CreatedAt))
+  case release.Prerelease:
+    ui.Printf("%s • %s released this %s\n", ui.Yellow("Pre-release"), author, utils.TimeAgo(release.PublishedAt))
+  default:
+    ui.Printf("%s released this %s\n", author, utils.TimeAgo(release.PublishedAt))
+  }
+
+  if body := strings.TrimSpace(release.Body); body != "" {
+    ui.Println("")
+    ui.Println(body)
+  }
+
+  if len(release.Assets) > 0 {
+    ui.Println("")
+    printHeader("Assets")
+    isTTY := ui.IsTerminal(os.Stdout)
+    table := ui.NewTablePrinter(ui.Stdout, isTTY, ui.TerminalWidth(os.Stdout))
+    for _, asset := range release.Assets {
+      table.AddField(asset.Name, nil)
+      table.AddField(humanSize(asset.Size), ui.Gray)
+      table.EndRow()
+    }
+    if err := table.Render(); err != nil {
+      return err
+    }
+  }
+  ui.Println("")
+
+  ui.Println(ui.Gray(fmt.Sprintf("View this release on GitHub: %s", release.HtmlUrl)))
+  return nil
+}
+
+func humanSize(size int64) string {
+  const unit = 1024
+  if size < unit {
+    return fmt.Sprintf("%d B", size)
+  }
+  div, exp := int64(unit), 0
+  for n := size / unit; n >= unit; n /= unit {
+    div *= unit
+    exp++
+  }
+  return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func releaseDownload(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+  client := github.NewClient(project.Host)
+
+  patterns, _ := cmd.Flags().GetStringSlice("pattern")
+  dir, _ := cmd.Flags().GetString("dir")
+  clobber, _ := cmd.Flags().GetBool("clobber")
+
+  for _, pattern := range patterns {
+    if _, err := filepath.Match(pattern, ""); err != nil {
+      return fmt.Errorf("invalid pattern %q: %s", pattern, err)
+    }
+  }
+
+  release, err := releaseFromArgs(client, project, args)
+  if err != nil {
+    return err
+  }
+
+  assets := []github.ReleaseAsset{}
+  for _, asset := range release.Assets {
+    if matchesAnyPattern(asset.Name, patterns) {
+      assets = append(assets, asset)
+    }
+  }
+  if len(assets) == 0 {
+    if len(patterns) > 0 {
+      return fmt.Errorf("no assets of %s match the given patterns", release.TagName)
+    }
+    return fmt.Errorf("release %s has no assets", release.TagName)
+  }
+
+  // Check every destination first so that nothing is downloaded when one of
+  // the files would be overwritten.
+  if !clobber {
+    for _, asset := range assets {
+      destination := filepath.Join(dir, asset.Name)
+      if _, err := os.Stat(destination); err == nil {
+        return fmt.Errorf("%s already exists; use --clobber to overwrite it", destination)
+      }
+    }
+  }
+
+  if err := os.MkdirAll(dir, 0755); err != nil {
+    return err
+  }
+  for _, asset := range assets {
+    ui.Errorf("Downloading %s\n", asset.Name)
+    if err := downloadAsset(client, &asset, filepath.Join(dir, asset.Name), clobber); err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+func matchesAnyPattern(name string, patterns []string) bool {
+  if len(patterns) == 0 {
+    return true
+  }
+  for _, pattern := range patterns {
+    if ok, _ := filepath.Match(pattern, name); ok {
+      return true
+    }
+  }
+  return false
+}
+
+func downloadAsset(client *github.Client, asset *github.ReleaseAsset, destination string, clobber bool) error {
+  flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+  if !clobber {
+    flags |= os.O_EXCL
+  }
+  file, err := os.OpenFile(destination, flags, 0644)
+  if os.IsExist(err) {
+    return fmt.Errorf("%s already exists; use --clobber to overwrite it", destination)
+  } else if err != nil {
+    return err
+  }
+
+  body, err := client.DownloadReleaseAsset(asset)
+  if err != nil {
+    file.Close()
+    os.Remove(destination)
+    return err
+  }
+  defer body.Close()
+
+  if _, err := io.Copy(file, body); err != nil {
+    file.Close()
+    return err
+  }
+  return file.Close()
+}
+
+func releaseUpload(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+  client := github.NewClient(project.Host)
+  clobber, _ := cmd.Flags().GetBool("clobber")
+
+  assets, err := parseReleaseAssetArgs(args[1:])
+  if err != nil {
+    return err
+  }
+
+  release, err := client.FetchRelease(project, args[0])
+  if err != nil {
+    return err
+  }
+
+  existing := map[string]*github.ReleaseAsset{}
+  for i, asset := range release.Assets {
+    existing[asset.Name] = &release.Assets[i]
+  }
+  for _, asset := range assets {
+    name := filepath.Base(asset.path)
+    if existing[name] != nil && !clobber {
+      return fmt.Errorf("asset %s already exists in release %s; use --clobber to replace it", name, release.TagName)
+    }
+  }
+
+  for _, asset := range assets {
+    if old := existing[filepath.Base(asset.path)]; old != nil {
+      if err := client.DeleteReleaseAsset(old); err != nil {
+        return err
+      }
+    }
+    ui.Errorf("Uploading %s\n", asset.path)
+    if _, err := client.UploadReleaseAsset(release, asset.path, asset.label); err != nil {
+      return err
+    }
+  }
+
+  ui.Println(release.HtmlUrl)
+  return nil
+}
+
+func releaseDelete(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+  client := github.NewClient(project.Host)
+
+  release, err := client.FetchRelease(project, args[0])
+  if err != nil {
+    return err
+  }
+
+  if err := client.DeleteRelease(release); err != nil {
+    return err
+  }
+
+  ui.Printf("Deleted release %s\n", release.TagName)
+  return nil
+}
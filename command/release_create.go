@@ -0,0 +1,205 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/git"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+  "io/ioutil"
+  "os"
+  "strings"
+)
+
+func init() {
+  releaseCmd.AddCommand(releaseCreateCmd)
+
+  releaseCreateCmd.Flags().StringP("title", "t", "", "Release title (default: the tag name)")
+  releaseCreateCmd.Flags().StringP("notes", "n", "", "Release notes")
+  releaseCreateCmd.Flags().StringP("notes-file", "F", "", "Read release notes from `file` (use \"-\" to read from standard input)")
+  releaseCreateCmd.Flags().BoolP("draft", "d", false, "Save the release as a draft instead of publishing it")
+  releaseCreateCmd.Flags().BoolP("prerelease", "p", false, "Mark the release as a prerelease")
+  releaseCreateCmd.Flags().String("target", "", "Target `branch` or full commit SHA (default: the default branch)")
+}
+
+var releaseCreateCmd = &cobra.Command{
+  Use: "create <tag> [<files>...]",
+  Short: "Create a new release",
+  Long: `Create a new GitHub release and upload files as its assets.
+
+Without --notes or --notes-file, the notes are generated from the commits
+since the previous tag. If --title is not given either, the release message
+is composed in the text editor starting from those notes.
+
+An asset can be given a display label by appending it after '#', as in
+"dist/app.tar.gz#Linux build".`,
+  Args: cobra.MinimumNArgs(1),
+  RunE: releaseCreate,
+}
+
+func releaseCreate(cmd *cobra.Command, args []string) error {
+  project, err := project()
+  if err != nil {
+    return err
+  }
+  client := github.NewClient(project.Host)
+
+  tagName := args[0]
+  title, _ := cmd.Flags().GetString("title")
+  notes, _ := cmd.Flags().GetString("notes")
+  notesFile, _ := cmd.Flags().GetString("notes-file")
+  draft, _ := cmd.Flags().GetBool("draft")
+  prerelease, _ := cmd.Flags().GetBool("prerelease")
+  target, _ := cmd.Flags().GetString("target")
+
+  if notes != "" && notesFile != "" {
+    return fmt.Errorf("specify only one of --notes or --notes-file")
+  }
+
+  assets, err := parseReleaseAssetArgs(args[1:])
+  if err != nil {
+    return err
+  }
+
+  if notesFile != "" {
+    var data []byte
+    if notesFile == "-" {
+      data, err = ioutil.ReadAll(os.Stdin)
+    } else {
+      data, err = ioutil.ReadFile(notesFile)
+    }
+    if err != nil {
+      return err
+    }
+    notes = string(data)
+  }
+
+  var editor *github.Editor
+  if notes == "" && notesFile == "" {
+    generated := generatedReleaseNotes(tagName, target)
+    if title == "" && ui.IsTerminal(os.Stdin) && ui.IsTerminal(os.Stdout) {
+      editor, err = github.NewEditor("RELEASE_EDITMSG", "release", tagName+"\n\n"+generated+"\n")
+      if err != nil {
+        return err
+      }
+      editor.AddCommentedSection(fmt.Sprintf(`Creating release %s for %s
+
+Write a message for this release. The first block of
+text is the title and the rest is the description.`, tagName, project))
+
+      title, notes, err = editor.EditTitleAndBody()
+      if err != nil {
+        return err
+      }
+      if title == "" {
+        editor.DeleteFile()
+        return fmt.Errorf("Aborting release due to empty release title")
+      }
+    } else {
+      notes = generated
+    }
+  }
+  if title == "" {
+    title = tagName
+  }
+
+  params := map[string]interface{}{
+    "tag_name":   tagName,
+    "name":       title,
+    "body":       strings.TrimSpace(notes),
+    "draft":      draft || len(assets) > 0,
+    "prerelease": prerelease,
+  }
+  if target != "" {
+    params["target_commitish"] = target
+  }
+
+  release, err := client.CreateRelease(project, params)
+  if err != nil {
+    if editor != nil {
+      ui.Errorf("The release message was saved to %s\n", editor.File)
+    }
+    return err
+  }
+  if editor != nil {
+    editor.DeleteFile()
+  }
+
+  // Assets are uploaded while the release is still a draft so that it is
+  // never published without them.
+  for _, asset := range assets {
+    ui.Errorf("Uploading %s\n", asset.path)
+    if _, err := client.UploadReleaseAsset(release, asset.path, asset.label); err != nil {
+      return err
+    }
+  }
+
+  if len(assets) > 0 && !draft {
+    release, err = client.UpdateRelease(release, map[string]interface{}{"draft": false})
+    if err != nil {
+      return err
+    }
+  }
+
+  ui.Println(release.HtmlUrl)
+  return nil
+}
+
+type releaseAssetArg struct {
+  path  string
+  label string
+}
+
+func parseReleaseAssetArg(arg string) releaseAssetArg {
+  if i := strings.Index(arg, "#"); i >= 0 {
+    return releaseAssetArg{path: arg[:i], label: arg[i+1:]}
+  }
+  return releaseAssetArg{path: arg}
+}
+
+// parseReleaseAssetArgs parses asset arguments, checking that each one is a
+// readable file before anything is sent to GitHub.
+func parseReleaseAssetArgs(args []string) ([]releaseAssetArg, error) {
+  assets := []releaseAssetArg{}
+  for _, arg := range args {
+    asset := parseReleaseAssetArg(arg)
+    if stat, err := os.Stat(asset.path); err != nil {
+      return nil, fmt.Errorf("unable to read asset %s: %s", asset.path, err)
+    } else if stat.IsDir() {
+      return nil, fmt.Errorf("asset %s is a directory", asset.path)
+    }
+    assets = append(assets, asset)
+  }
+  return assets, nil
+}
+
+// generatedReleaseNotes lists the commits since the tag that precedes the
+// release. When the tag does not exist locally yet, the release is assumed
+// to be cut from target, or from HEAD when no target is given.
+func generatedReleaseNotes(tagName, target string) string {
+  head := "HEAD"
+  if target != "" {
+    head = target
+  }
+  previousTag, err := git.LatestTag(head)
+  if git.HasTag(tagName) {
+    head = tagName
+    previousTag, err = git.LatestTag(tagName + "^")
+  }
+
+  revRange := head
+  if err == nil {
+    revRange = fmt.Sprintf("%s..%s", previousTag, head)
+  }
+
+  subjects, err := git.LogSubjects(revRange)
+  if err != nil {
+    return ""
+  }
+
+  lines := make([]string, 0, len(subjects))
+  for _, subject := range subjects {
+    lines = append(lines, "- "+subject)
+  }
+  return strings.Join(lines, "\n")
+}
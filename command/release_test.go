@@ -0,0 +1,68 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/github"
+  "io/ioutil"
+  "net/http"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+)
+
+func TestParseReleaseAssetArg(t *testing.T) {
+  tests := []struct {
+    arg   string
+    path  string
+    label string
+  }{
+    {"dist/app.tar.gz", "dist/app.tar.gz", ""},
+    {"dist/app.tar.gz#Linux build", "dist/app.tar.gz", "Linux build"},
+    {"notes.txt#", "notes.txt", ""},
+  }
+
+  for _, tt := range tests {
+    got := parseReleaseAssetArg(tt.arg)
+    if got.path != tt.path || got.label != tt.label {
+      t.Errorf("parseReleaseAssetArg(%q) = %+v, want path %q and label %q", tt.arg, got, tt.path, tt.label)
+    }
+  }
+}
+
+func TestDownloadAssetClobber(t *testing.T) {
+  requests := 0
+  handler := func(w http.ResponseWriter, r *http.Request) {
+    requests++
+    fmt.Fprint(w, "new")
+  }
+  defer stubAPI(t, "", handler)()
+
+  dir, err := ioutil.TempDir("", "gh-download")
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer os.RemoveAll(dir)
+  destination := filepath.Join(dir, "app.tar.gz")
+  if err := ioutil.WriteFile(destination, []byte("old"), 0644); err != nil {
+    t.Fatal(err)
+  }
+
+  client := github.NewClient("github.com")
+  asset := &github.ReleaseAsset{Name: "app.tar.gz", ApiUrl: "https://api.github.com/repos/octo/hello/releases/assets/1"}
+
+  err = downloadAsset(client, asset, destination, false)
+  if err == nil || !strings.Contains(err.Error(), "--clobber") {
+    t.Errorf("downloadAsset() error = %v, want a hint about --clobber", err)
+  }
+  if data, _ := ioutil.ReadFile(destination); string(data) != "old" || requests != 0 {
+    t.Errorf("existing file changed to %q after %d requests", data, requests)
+  }
+
+  if err := downloadAsset(client, asset, destination, true); err != nil {
+    t.Fatalf("downloadAsset() with clobber error: %s", err)
+  }
+  if data, _ := ioutil.ReadFile(destination); string(data) != "new" {
+    t.Errorf("file contains %q after clobbering, want %q", data, "new")
+  }
+}
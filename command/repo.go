@@ -0,0 +1,261 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/git"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/npathai/github-cli-clone/utils"
+  "github.com/spf13/cobra"
+  "net/url"
+  "strings"
+  "time"
+)
+
+func init() {
+  RootCmd.AddCommand(repoCmd)
+  repoCmd.AddCommand(repoViewCmd)
+  repoCmd.AddCommand(repoCloneCmd)
+  repoCmd.AddCommand(repoForkCmd)
+
+  repoViewCmd.Flags().BoolP("web", "w", false, "Open the repository in the browser")
+
+  repoForkCmd.Flags().String("remote-name", "origin", "The `name` of the git remote to add for the fork")
+}
+
+var repoCmd = &cobra.Command{
+  Use: "repo",
+  Short: "Work with repositories",
+  Long: "This command allows you to work with repositories",
+  Args: cobra.MinimumNArgs(1),
+}
+
+var repoViewCmd = &cobra.Command{
+  Use: "view [<owner>/<repo> | <url>]",
+  Short: "View a repository",
+  Long: `Display the description and the README of a repository.
+
+Without an argument, the repository of the current directory is shown.`,
+  Args: cobra.MaximumNArgs(1),
+  RunE: repoView,
+}
+
+var repoCloneCmd = &cobra.Command{
+  Use: "clone {<owner>/<repo> | <url>} [<directory>] [-- <git clone flags>]",
+  Short: "Clone a repository locally",
+  Long: `Clone a GitHub repository locally.
+
+The clone uses an SSH URL when the protocol configured for the host is
+"ssh", and HTTPS otherwise. When the repository is a fork, its parent is
+added as the "upstream" remote.`,
+  Args: cobra.MinimumNArgs(1),
+  RunE: repoClone,
+}
+
+var repoForkCmd = &cobra.Command{
+  Use: "fork [<owner>/<repo> | <url>]",
+  Short: "Create a fork of a repository",
+  Long: `Fork a repository into your account.
+
+Without an argument, the repository of the current directory is forked and
+a git remote is added for the fork. If the remote name is taken by the
+remote of the original repository, that remote is renamed to "upstream".`,
+  Args: cobra.MaximumNArgs(1),
+  RunE: repoFork,
+}
+
+// repoFromArgs returns the repository given as argument, or the one of the
+// current directory.
+func repoFromArgs(args []string) (*github.Project, error) {
+  if len(args) > 0 {
+    return repoFromArg(args[0])
+  }
+  return project()
+}
+
+// repoFromArg parses `owner/repo`, a bare repository name owned by the
+// current user, or a repository URL.
+func repoFromArg(arg string) (*github.Project, error) {
+  if strings.HasPrefix(arg, "https://") || strings.HasPrefix(arg, "http://") {
+    u, err := url.Parse(arg)
+    if err != nil {
+      return nil, err
+    }
+    return github.NewProjectFromURL(u)
+  }
+
+  if strings.Contains(arg, "/") {
+    project := github.NewProject(arg, "", "")
+    if project.Owner == "" || project.Name == "" {
+      return nil, fmt.Errorf("invalid repository: %q", arg)
+    }
+    return project, nil
+  }
+  return github.NewProject("", arg, ""), nil
+}
+
+func repoView(cmd *cobra.Command, args []string) error {
+  project, err := repoFromArgs(args)
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  repo, err := client.Repository(project)
+  if err != nil {
+    return err
+  }
+
+  web, _ := cmd.Flags().GetBool("web")
+  if web {
+    ui.Errorf("Opening %s in your browser.\n", repo.HtmlUrl)
+    return utils.OpenInBrowser(repo.HtmlUrl)
+  }
+
+  readme, err := client.Readme(project)
+  if err != nil {
+    return err
+  }
+
+  ui.Println(ui.Bold(repo.FullName))
+  if repo.Parent != nil {
+    ui.Println(ui.Gray(fmt.Sprintf("forked from %s", repo.Parent.FullName)))
+  }
+  if repo.Description != "" {
+    ui.Println(repo.Description)
+  }
+  ui.Println("")
+
+  if readme = strings.TrimSpace(readme); readme != "" {
+    ui.Println(readme)
+  } else {
+    printMessage("This repository does not have a README")
+  }
+  ui.Println("")
+
+  ui.Println(ui.Gray(fmt.Sprintf("View this repository on GitHub: %s", repo.HtmlUrl)))
+  return nil
+}
+
+func repoClone(cmd *cobra.Command, args []string) error {
+  repoArgs, gitArgs := args, []string{}
+  if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+    repoArgs, gitArgs = args[:dash], args[dash:]
+  }
+  if len(repoArgs) == 0 || len(repoArgs) > 2 {
+    return fmt.Errorf("expected a repository and an optional directory")
+  }
+
+  project, err := repoFromArg(repoArgs[0])
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  repo, err := client.Repository(project)
+  if err != nil {
+    return err
+  }
+
+  isSSH := github.CurrentConfig().Find(project.Host).IsSSH()
+  dir := repo.Name
+  if len(repoArgs) > 1 {
+    dir = repoArgs[1]
+  }
+
+  if err := git.Clone(project.GitURL(repo.Name, repo.Owner.Login, isSSH), dir, gitArgs...); err != nil {
+    return err
+  }
+
+  if repo.Parent != nil && repo.Parent.Owner != nil {
+    upstreamURL := project.GitURL(repo.Parent.Name, repo.Parent.Owner.Login, isSSH)
+    if err := git.Spawn("-C", dir, "remote", "add", "-f", "upstream", upstreamURL); err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+func repoFork(cmd *cobra.Command, args []string) error {
+  project, err := repoFromArgs(args)
+  if err != nil {
+    return err
+  }
+
+  client := github.NewClient(project.Host)
+  fork, err := client.ForkRepository(project)
+  if err != nil {
+    return err
+  }
+
+  forkProject := github.NewProject(fork.Owner.Login, fork.Name, project.Host)
+  if forkProject.SameAs(project) {
+    return fmt.Errorf("Error: %s is already owned by you", project)
+  }
+
+  ui.Errorf("Waiting for %s to be created...\n", forkProject)
+  if err := waitForRepository(client, forkProject); err != nil {
+    return err
+  }
+  ui.Printf("Forked %s to %s\n", project, ui.Bold(forkProject.String()))
+
+  if len(args) > 0 {
+    return nil
+  }
+
+  remoteName, _ := cmd.Flags().GetString("remote-name")
+  return addForkRemote(project, forkProject, remoteName)
+}
+
+// addForkRemote adds a remote for the fork. A remote of the original project
+// occupying the name is moved out of the way to "upstream".
+func addForkRemote(project, forkProject *github.Project, remoteName string) error {
+  if remote, err := remoteForProject(forkProject); err == nil {
+    ui.Printf("Using existing remote %s for the fork\n", remote.Name)
+    return nil
+  }
+
+  baseRemote, err := remoteForProject(project)
+  if err != nil {
+    return err
+  }
+
+  if existing, err := remoteByName(remoteName); err == nil {
+    if existing.Name != baseRemote.Name {
+      return fmt.Errorf("Error: a git remote named %s already exists", remoteName)
+    }
+    if _, err := remoteByName("upstream"); err == nil {
+      return fmt.Errorf("Error: a git remote named %s already exists; use --remote-name to pick another name for the fork", remoteName)
+    }
+    if err := git.RenameRemote(remoteName, "upstream"); err != nil {
+      return err
+    }
+    ui.Printf("Renamed remote %s to upstream\n", remoteName)
+  }
+
+  isSSH := baseRemote.URL != nil && baseRemote.URL.Scheme == "ssh"
+  if err := git.AddRemote(remoteName, forkProject.GitURL("", "", isSSH)); err != nil {
+    return err
+  }
+  ui.Printf("Added remote %s for %s\n", remoteName, forkProject)
+  return nil
+}
+
+var (
+  forkPollInterval = 2 * time.Second
+  forkPollTimeout  = 2 * time.Minute
+)
+
+// waitForRepository polls until a freshly created repository can be read
+// back from the API, since GitHub creates forks asynchronously.
+func waitForRepository(client *github.Client, project *github.Project) error {
+  deadline := time.Now().Add(forkPollTimeout)
+  for {
+    if _, err := client.Repository(project); err == nil {
+      return nil
+    } else if time.Now().After(deadline) {
+      return fmt.Errorf("timed out waiting for %s to be created: %s", project, err)
+    }
+    time.Sleep(forkPollInterval)
+  }
+}
@@ -0,0 +1,121 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/git"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+  "github.com/spf13/cobra"
+  "path/filepath"
+  "strings"
+)
+
+func init() {
+  repoCmd.AddCommand(repoCreateCmd)
+
+  repoCreateCmd.Flags().Bool("private", false, "Make the new repository private")
+  repoCreateCmd.Flags().Bool("public", false, "Make the new repository public (default)")
+  repoCreateCmd.Flags().StringP("description", "d", "", "Description of the repository")
+  repoCreateCmd.Flags().String("homepage", "", "Repository home page `URL`")
+  repoCreateCmd.Flags().StringP("org", "o", "", "Create the repository in an organization")
+  repoCreateCmd.Flags().StringP("team", "t", "", "The `name` of the organization team to be granted access")
+  repoCreateCmd.Flags().Bool("enable-wiki", true, "Enable the wiki in the new repository")
+  repoCreateCmd.Flags().String("remote-name", "", "The `name` of the git remote to add (default: origin)")
+  repoCreateCmd.Flags().BoolP("push", "p", false, "Push the current branch to the new repository")
+}
+
+var repoCreateCmd = &cobra.Command{
+  Use: "create [<name> | <org>/<name>]",
+  Short: "Create a new repository",
+  Long: `Create a GitHub repository for the current local directory.
+
+The name defaults to the name of the directory. After the repository is
+created it is added as the "origin" git remote, which is refused when an
+"origin" remote already exists unless --remote-name picks another name.`,
+  Args: cobra.MaximumNArgs(1),
+  RunE: repoCreate,
+}
+
+func repoCreate(cmd *cobra.Command, args []string) error {
+  private, _ := cmd.Flags().GetBool("private")
+  public, _ := cmd.Flags().GetBool("public")
+  description, _ := cmd.Flags().GetString("description")
+  homepage, _ := cmd.Flags().GetString("homepage")
+  org, _ := cmd.Flags().GetString("org")
+  team, _ := cmd.Flags().GetString("team")
+  enableWiki, _ := cmd.Flags().GetBool("enable-wiki")
+  remoteName, _ := cmd.Flags().GetString("remote-name")
+  push, _ := cmd.Flags().GetBool("push")
+
+  if private && public {
+    return fmt.Errorf("specify only one of --private or --public")
+  }
+
+  workdir, err := git.WorkdirName()
+  if err != nil {
+    return fmt.Errorf("Aborted: not a git repository; run `git init` first")
+  }
+
+  name := filepath.Base(workdir)
+  if len(args) > 0 {
+    name = args[0]
+    if i := strings.Index(name, "/"); i >= 0 {
+      if org != "" && !strings.EqualFold(org, name[:i]) {
+        return fmt.Errorf("the repository owner %q does not match --org %q", name[:i], org)
+      }
+      org, name = name[:i], name[i+1:]
+    }
+  }
+  if team != "" && org == "" {
+    return fmt.Errorf("--team requires an organization")
+  }
+
+  if remoteName == "" {
+    remoteName = "origin"
+    if remote, err := remoteByName(remoteName); err == nil {
+      return fmt.Errorf("Aborted: a git remote named %s already exists; use --remote-name to add the new repository under another name", remote.Name)
+    }
+  } else if _, err := remoteByName(remoteName); err == nil {
+    return fmt.Errorf("Aborted: a git remote named %s already exists", remoteName)
+  }
+
+  host := github.DefaultGitHubHost()
+  client := github.NewClient(host)
+
+  params := map[string]interface{}{
+    "name":     name,
+    "private":  private,
+    "has_wiki": enableWiki,
+  }
+  if description != "" {
+    params["description"] = description
+  }
+  if homepage != "" {
+    params["homepage"] = homepage
+  }
+  if team != "" {
+    t, err := client.Team(org, team)
+    if err != nil {
+      return err
+    }
+    params["team_id"] = t.Id
+  }
+
+  repo, err := client.CreateRepository(org, params)
+  if err != nil {
+    return err
+  }
+  ui.Println(repo.HtmlUrl)
+
+  project := github.NewProject(repo.Owner.Login, repo.Name, host)
+  isSSH := github.CurrentConfig().Find(project.Host).IsSSH()
+  if err := git.AddRemote(remoteName, project.GitURL("", "", isSSH)); err != nil {
+    return err
+  }
+  ui.Errorf("Added remote %s\n", remoteName)
+
+  if push {
+    return git.PushUpstream(remoteName)
+  }
+  return nil
+}
@@ -11,7 +11,19 @@ var RootCmd = &cobra.Command{
   Short: "GitHub CLI",
   Long: `Do things with GitHub from your terminal`,
   Args: cobra.MinimumNArgs(1),
+  SilenceErrors: true,
+  SilenceUsage: true,
   Run: func(cmd *cobra.Command, args []string) {
     fmt.Println("root")
   },
-}
\ No newline at end of file
+}
+
+// ExitError ends the program with the given exit code without printing an
+// error message, for commands whose outcome is meant to be scripted.
+type ExitError struct {
+  Code int
+}
+
+func (e *ExitError) Error() string {
+  return fmt.Sprintf("exit status %d", e.Code)
+}
@@ -0,0 +1,59 @@
+package command
+
+import (
+  "fmt"
+  "github.com/npathai/github-cli-clone/git"
+  "github.com/npathai/github-cli-clone/github"
+  "github.com/npathai/github-cli-clone/ui"
+)
+
+// chooseTemplate asks which of the repository's templates to start from.
+// It returns nil when there are none or the user picks a blank message, and
+// when there are several but it can't ask because it isn't interactive.
+func chooseTemplate(find func(string) []*github.Template, kind string, interactive bool) (*github.Template, error) {
+  workdir, err := git.WorkdirName()
+  if err != nil {
+    return nil, nil
+  }
+
+  templates := find(workdir)
+  switch len(templates) {
+  case 0:
+    return nil, nil
+  case 1:
+    return templates[0], nil
+  }
+  if !interactive {
+    return nil, nil
+  }
+
+  options := make([]string, 0, len(templates)+1)
+  for _, t := range templates {
+    option := t.Name
+    if t.About != "" {
+      option = fmt.Sprintf("%s - %s", t.Name, t.About)
+    }
+    options = append(options, option)
+  }
+  options = append(options, fmt.Sprintf("Open a blank %s", kind))
+
+  i, err := ui.Select("Choose a template:", options)
+  if err != nil {
+    return nil, err
+  }
+  if i == len(templates) {
+    return nil, nil
+  }
+  return templates[i], nil
+}
+
+// mergeValues appends the values of extra that are not already in values,
+// ignoring case.
+func mergeValues(values, extra []string) []string {
+  for _, v := range extra {
+    if !containsFold(values, v) {
+      values = append(values, v)
+    }
+  }
+  return values
+}
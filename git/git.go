@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -57,6 +58,14 @@ func Head() (string, error) {
 	return BranchAtRef("HEAD")
 }
 
+func CurrentBranch() (string, error) {
+	head, err := Head()
+	if err != nil {
+		return "", fmt.Errorf("Aborted: not currently on any branch.")
+	}
+	return strings.TrimPrefix(head, "refs/heads/"), nil
+}
+
 func Dir() (string, error) {
 	if cachedDir != "" {
 		return cachedDir, nil
@@ -106,3 +115,275 @@ func firstLine(output []byte) string {
 	}
 	return string(output)
 }
+
+func Config(name string) (string, error) {
+	configCmd := exec.Command("git", "config", name)
+	configCmd.Stderr = nil
+	output, err := configCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("Unknown config %s", name)
+	}
+	return firstLine(output), nil
+}
+
+func Editor() (string, error) {
+	varCmd := exec.Command("git", "var", "GIT_EDITOR")
+	varCmd.Stderr = nil
+	output, err := varCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("Can't load git var: GIT_EDITOR")
+	}
+	return os.ExpandEnv(firstLine(output)), nil
+}
+
+func CommentChar(text string) (string, error) {
+	char, err := Config("core.commentchar")
+	if err != nil {
+		return "#", nil
+	} else if char == "auto" {
+		lines := strings.Split(text, "\n")
+		commentCharCandidates := strings.Split("#;@!$%^&|:", "")
+	candidateLoop:
+		for _, candidate := range commentCharCandidates {
+			for _, line := range lines {
+				if strings.HasPrefix(line, candidate) {
+					continue candidateLoop
+				}
+			}
+			return candidate, nil
+		}
+		return "", fmt.Errorf("unable to select a comment character that is not used in the current message")
+	} else {
+		return char, nil
+	}
+}
+
+func RefList(a, b string) ([]string, error) {
+	refCmd := exec.Command("git", "rev-list", "--reverse", fmt.Sprintf("%s...%s", a, b))
+	refCmd.Stderr = nil
+	output, err := refCmd.Output()
+	if err != nil {
+		return []string{}, fmt.Errorf("Can't load rev-list for %s...%s", a, b)
+	}
+	return outputLines(output), nil
+}
+
+func Show(sha string) (string, error) {
+	showCmd := exec.Command("git", "-c", "log.showSignature=false", "show", "-s", "--format=%s%n%+b", sha)
+	showCmd.Stderr = nil
+	output, err := showCmd.Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// Spawn runs a git command attached to the current terminal so that progress
+// and errors are shown to the user.
+func Spawn(args ...string) error {
+	spawnCmd := exec.Command("git", append(GlobalFlags, args...)...)
+	spawnCmd.Stdin = os.Stdin
+	spawnCmd.Stdout = os.Stdout
+	spawnCmd.Stderr = os.Stderr
+	return spawnCmd.Run()
+}
+
+func Fetch(remote string, refspecs ...string) error {
+	return Spawn(append([]string{"fetch", remote}, refspecs...)...)
+}
+
+func Checkout(branch string) error {
+	return Spawn("checkout", branch)
+}
+
+// CheckoutNewBranch creates a branch at startPoint and checks it out. With
+// force, an existing branch of the same name is reset to startPoint.
+func CheckoutNewBranch(branch, startPoint string, force bool) error {
+	flag := "-b"
+	if force {
+		flag = "-B"
+	}
+	return Spawn("checkout", flag, branch, "--no-track", startPoint)
+}
+
+func MergeFastForward(ref string) error {
+	return Spawn("merge", "--ff-only", ref)
+}
+
+func HasLocalBranch(branch string) bool {
+	refCmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+	return refCmd.Run() == nil
+}
+
+func IsAncestor(ancestor, descendant string) bool {
+	mergeBaseCmd := exec.Command("git", "merge-base", "--is-ancestor", ancestor, descendant)
+	return mergeBaseCmd.Run() == nil
+}
+
+func SetConfig(name, value string) error {
+	configCmd := exec.Command("git", "config", name, value)
+	configCmd.Stderr = nil
+	if err := configCmd.Run(); err != nil {
+		return fmt.Errorf("Can't set git config %s", name)
+	}
+	return nil
+}
+
+func AddRemote(name, url string) error {
+	remoteCmd := exec.Command("git", "remote", "add", name, url)
+	remoteCmd.Stderr = nil
+	if err := remoteCmd.Run(); err != nil {
+		return fmt.Errorf("Can't add git remote %s", name)
+	}
+	return nil
+}
+
+// DeleteLocalBranch deletes a branch that is merged into its upstream or
+// HEAD. With force, the branch is deleted regardless.
+func DeleteLocalBranch(branch string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	branchCmd := exec.Command("git", "branch", flag, branch)
+	if output, err := branchCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s", firstLine(bytes.TrimSpace(output)))
+	}
+	return nil
+}
+
+// Upstream is the remote and the ref on it that a local branch tracks.
+type Upstream struct {
+	Remote string
+	Merge  string
+}
+
+// BranchUpstreams returns the upstream configured for every local branch
+// that has one, keyed by branch name.
+func BranchUpstreams() (map[string]Upstream, error) {
+	configCmd := exec.Command("git", "config", "--get-regexp", `^branch\..*\.(remote|merge)$`)
+	configCmd.Stderr = nil
+	output, err := configCmd.Output()
+	upstreams := map[string]Upstream{}
+	if err != nil {
+		// git config exits with 1 when nothing matches.
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return upstreams, nil
+		}
+		return nil, err
+	}
+
+	for _, line := range outputLines(output) {
+		parts := strings.SplitN(line, " ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimPrefix(parts[0], "branch.")
+		i := strings.LastIndex(key, ".")
+		branch, name := key[:i], key[i+1:]
+		upstream := upstreams[branch]
+		if name == "remote" {
+			upstream.Remote = parts[1]
+		} else {
+			upstream.Merge = parts[1]
+		}
+		upstreams[branch] = upstream
+	}
+	for branch, upstream := range upstreams {
+		if upstream.Remote == "" || upstream.Merge == "" {
+			delete(upstreams, branch)
+		}
+	}
+	return upstreams, nil
+}
+
+func WorkdirName() (string, error) {
+	toplevelCmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	toplevelCmd.Stderr = nil
+	output, err := toplevelCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine git working directory")
+	}
+	return firstLine(output), nil
+}
+
+func RenameRemote(oldName, newName string) error {
+	remoteCmd := exec.Command("git", "remote", "rename", oldName, newName)
+	remoteCmd.Stderr = nil
+	if err := remoteCmd.Run(); err != nil {
+		return fmt.Errorf("Can't rename git remote %s to %s", oldName, newName)
+	}
+	return nil
+}
+
+// Clone clones url into dir, passing any extra arguments through to
+// `git clone`.
+func Clone(url, dir string, args ...string) error {
+	cloneArgs := append([]string{"clone"}, args...)
+	cloneArgs = append(cloneArgs, url)
+	if dir != "" {
+		cloneArgs = append(cloneArgs, dir)
+	}
+	return Spawn(cloneArgs...)
+}
+
+// PushUpstream pushes the current branch to remote and sets it as the
+// upstream of the branch.
+func PushUpstream(remote string) error {
+	return Spawn("push", "--set-upstream", remote, "HEAD")
+}
+
+// LatestTag returns the most recent tag reachable from ref.
+func LatestTag(ref string) (string, error) {
+	describeCmd := exec.Command("git", "describe", "--tags", "--abbrev=0", ref)
+	describeCmd.Stderr = nil
+	output, err := describeCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("No tags reachable from %s", ref)
+	}
+	return firstLine(output), nil
+}
+
+// LogSubjects returns the subject lines of the commits in revRange, newest
+// first.
+func LogSubjects(revRange string) ([]string, error) {
+	logCmd := exec.Command("git", "-c", "log.showSignature=false", "log", "--no-merges", "--format=%s", revRange)
+	logCmd.Stderr = nil
+	output, err := logCmd.Output()
+	if err != nil {
+		return []string{}, fmt.Errorf("Can't load git log for %s", revRange)
+	}
+	return outputLines(output), nil
+}
+
+func HasTag(tag string) bool {
+	refCmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/tags/"+tag)
+	return refCmd.Run() == nil
+}
+
+// Credential runs `git credential <action>` with the given attributes, where
+// action is one of fill, approve or reject. Prompting on the terminal is
+// disabled, so fill fails when no helper knows the credential.
+func Credential(action string, attributes map[string]string) (map[string]string, error) {
+	var input bytes.Buffer
+	for _, key := range []string{"protocol", "host", "username", "password"} {
+		if value, ok := attributes[key]; ok {
+			fmt.Fprintf(&input, "%s=%s\n", key, value)
+		}
+	}
+	input.WriteString("\n")
+
+	credentialCmd := exec.Command("git", "credential", action)
+	credentialCmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	credentialCmd.Stdin = &input
+	credentialCmd.Stderr = nil
+	output, err := credentialCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git credential %s failed", action)
+	}
+
+	result := map[string]string{}
+	for _, line := range outputLines(output) {
+		if i := strings.Index(line, "="); i > 0 {
+			result[line[:i]] = line[i+1:]
+		}
+	}
+	return result, nil
+}
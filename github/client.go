@@ -2,21 +2,21 @@ package github
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"fmt"
-	"net"
+	"io"
+	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 )
 
 const (
 	GitHubHost string = "github.com"
-	OAuthAppURL string = "https://hub.github.com/"
 )
 
 type User struct {
@@ -26,6 +26,7 @@ type User struct {
 type Repository struct {
 	Name          string                 `json:"name"`
 	FullName      string                 `json:"full_name"`
+	Description   string                 `json:"description"`
 	Parent        *Repository            `json:"parent"`
 	Owner         *User                  `json:"owner"`
 	Private       bool                   `json:"private"`
@@ -59,12 +60,75 @@ type Milestone struct {
 }
 
 type Team struct {
-	Name string `json:"name"`
-	Slug string `json:"slug"`
+	Id           int    `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	Organization *User  `json:"organization"`
+}
+
+type Review struct {
+	Id          int       `json:"id"`
+	User        *User     `json:"user"`
+	Body        string    `json:"body"`
+	State       string    `json:"state"`
+	HtmlUrl     string    `json:"html_url"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+type Comment struct {
+	Id          int       `json:"id"`
+	User        *User     `json:"user"`
+	Body        string    `json:"body"`
+	Path        string    `json:"path"`
+	Line        int       `json:"line"`
+	InReplyToId int       `json:"in_reply_to_id"`
+	HtmlUrl     string    `json:"html_url"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ReviewComment struct {
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Side      string `json:"side,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+	StartSide string `json:"start_side,omitempty"`
+	Body      string `json:"body"`
+}
+
+type CommitStatus struct {
+	State       string    `json:"state"`
+	Context     string    `json:"context"`
+	Description string    `json:"description"`
+	TargetUrl   string    `json:"target_url"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CheckRun struct {
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+	Conclusion  string    `json:"conclusion"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	HtmlUrl     string    `json:"html_url"`
+	DetailsUrl  string    `json:"details_url"`
+}
+
+type checkRunsPage struct {
+	TotalCount int        `json:"total_count"`
+	CheckRuns  []CheckRun `json:"check_runs"`
+}
+
+type CombinedStatus struct {
+	State    string         `json:"state"`
+	Sha      string         `json:"sha"`
+	Statuses []CommitStatus `json:"statuses"`
 }
 
 type Issue struct {
 	Number int    `json:"number"`
+	NodeId string `json:"node_id"`
 	State  string `json:"state"`
 	Title  string `json:"title"`
 	Body   string `json:"body"`
@@ -97,6 +161,35 @@ type Issue struct {
 
 type PullRequest Issue
 
+type Release struct {
+	Id              int            `json:"id"`
+	TagName         string         `json:"tag_name"`
+	TargetCommitish string         `json:"target_commitish"`
+	Name            string         `json:"name"`
+	Body            string         `json:"body"`
+	Draft           bool           `json:"draft"`
+	Prerelease      bool           `json:"prerelease"`
+	Author          *User          `json:"author"`
+	Assets          []ReleaseAsset `json:"assets"`
+	CreatedAt       time.Time      `json:"created_at"`
+	PublishedAt     time.Time      `json:"published_at"`
+	ApiUrl          string         `json:"url"`
+	HtmlUrl         string         `json:"html_url"`
+	UploadUrl       string         `json:"upload_url"`
+}
+
+type ReleaseAsset struct {
+	Id            int    `json:"id"`
+	Name          string `json:"name"`
+	Label         string `json:"label"`
+	State         string `json:"state"`
+	ContentType   string `json:"content_type"`
+	Size          int64  `json:"size"`
+	DownloadCount int    `json:"download_count"`
+	ApiUrl        string `json:"url"`
+	DownloadUrl   string `json:"browser_download_url"`
+}
+
 func NewClient(host string) *Client {
 	return newClientWithHost(&Host{Host: host})
 }
@@ -139,8 +232,8 @@ func (client *Client) absolute(host string) *url.URL {
 	u, err := url.Parse("https://" + host + "/")
 	if err != nil {
 		panic(err)
-	} else if client.Host != nil && client.Host.Protocol != "" {
-		u.Scheme = client.Host.Protocol
+	} else if protocol := client.Host.webProtocol(); protocol != "" {
+		u.Scheme = protocol
 	}
 	return u
 }
@@ -200,7 +293,7 @@ filter func(pr *PullRequest) bool) (prs []PullRequest, err error) {
 	var res *simpleResponse
 
 	for path != "" {
-		res, err :=	api.GetFile(path, draftsType)
+		res, err = api.GetFile(path, draftsType)
 		if err = checkStatus(200, "fetching pull requests", res, err); err != nil {
 			return
 		}
@@ -224,222 +317,1133 @@ filter func(pr *PullRequest) bool) (prs []PullRequest, err error) {
 	return
 }
 
-func addQuery(path string, params map[string]interface{}) string {
-	if len(params) == 0 {
-		return path
-	}
+// PullRequestStatus is a pull request along with its reviews and the
+// combined state of its checks, as shown by pr status.
+type PullRequestStatus struct {
+	PullRequest
+	Reviews     []Review
+	ChecksState string
+}
 
-	query := url.Values{}
-	for key, val := range params {
-		switch v := val.(type) {
-		case string:
-			query.Add(key, v)
-		case nil:
-			query.Add(key, "")
-		case int:
-			query.Add(key, fmt.Sprintf("%d", v))
-		case bool:
-			query.Add(key, fmt.Sprintf("%v", v))
-		}
+// pullRequestStatusFields are the GraphQL fields of a pull request that make
+// up a PullRequestStatus, so that it takes a single request to get them.
+const pullRequestStatusFields = `
+	number title state isDraft url mergedAt headRefName headRefOid
+	headRepositoryOwner { login }
+	reviewRequests(first: 100) { nodes { requestedReviewer { ... on User { login } ... on Team { slug } } } }
+	reviews(last: 100) { nodes { state author { login } } }
+	commits(last: 1) { nodes { commit { statusCheckRollup { state } } } }`
+
+type pullRequestStatusNode struct {
+	Number              int        `json:"number"`
+	Title               string     `json:"title"`
+	State               string     `json:"state"`
+	IsDraft             bool       `json:"isDraft"`
+	Url                 string     `json:"url"`
+	MergedAt            *time.Time `json:"mergedAt"`
+	HeadRefName         string     `json:"headRefName"`
+	HeadRefOid          string     `json:"headRefOid"`
+	HeadRepositoryOwner *User      `json:"headRepositoryOwner"`
+	ReviewRequests      struct {
+		Nodes []struct {
+			RequestedReviewer struct {
+				Login string `json:"login"`
+				Slug  string `json:"slug"`
+			} `json:"requestedReviewer"`
+		} `json:"nodes"`
+	} `json:"reviewRequests"`
+	Reviews struct {
+		Nodes []struct {
+			State  string `json:"state"`
+			Author *User  `json:"author"`
+		} `json:"nodes"`
+	} `json:"reviews"`
+	Commits struct {
+		Nodes []struct {
+			Commit struct {
+				StatusCheckRollup *struct {
+					State string `json:"state"`
+				} `json:"statusCheckRollup"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"commits"`
+}
+
+// status converts the GraphQL representation to the REST one used by the
+// rest of the client. The state of merged pull requests is "closed", as in
+// REST.
+func (node *pullRequestStatusNode) status() PullRequestStatus {
+	pr := PullRequest{
+		Number:  node.Number,
+		Title:   node.Title,
+		State:   "open",
+		Draft:   node.IsDraft,
+		HtmlUrl: node.Url,
+		Head:    &PullRequestSpec{Ref: node.HeadRefName, Sha: node.HeadRefOid},
 	}
-	sep := "?"
-	if strings.Contains(path, sep) {
-		sep = "&"
+	if node.State != "OPEN" {
+		pr.State = "closed"
+	}
+	if node.MergedAt != nil {
+		pr.MergedAt = *node.MergedAt
+	}
+	if owner := node.HeadRepositoryOwner; owner != nil {
+		pr.Head.Label = owner.Login + ":" + node.HeadRefName
+		pr.Head.Repo = &Repository{Owner: owner}
 	}
-	return path + sep + query.Encode()
-}
 
-func perPage(limit, max int) int {
-	if limit > 0 {
-		limit = limit + (limit / 2)
-		if limit < max {
-			return limit
+	for _, request := range node.ReviewRequests.Nodes {
+		if reviewer := request.RequestedReviewer; reviewer.Slug != "" {
+			pr.RequestedTeams = append(pr.RequestedTeams, Team{Slug: reviewer.Slug})
+		} else if reviewer.Login != "" {
+			pr.RequestedReviewers = append(pr.RequestedReviewers, User{Login: reviewer.Login})
 		}
 	}
-	return max
-}
 
-func checkStatus(expectedStatus int, action string, response *simpleResponse, err error) error {
-	if err != nil {
-		return fmt.Errorf("error %s: %s", action, err.Error())
-	} else if response.StatusCode != expectedStatus {
-		errInfo, err := response.ErrorInfo()
-		if err == nil {
-			return FormatError(action, errInfo)
-		} else {
-			return fmt.Errorf("error %s: %s (HTTP %d)", action, err.Error(), response.StatusCode)
+	status := PullRequestStatus{PullRequest: pr, Reviews: []Review{}}
+	for _, review := range node.Reviews.Nodes {
+		status.Reviews = append(status.Reviews, Review{State: review.State, User: review.Author})
+	}
+	if len(node.Commits.Nodes) > 0 {
+		if rollup := node.Commits.Nodes[0].Commit.StatusCheckRollup; rollup != nil {
+			status.ChecksState = rollup.State
 		}
-	} else {
-		return nil
 	}
+	return status
 }
 
-func FormatError(action string, err error) (ee error) {
-	switch e := err.(type) {
-	default:
-		ee = err
-	case *errorInfo:
-		statusCode := e.Response.StatusCode
-		var reason string
-		if s := strings.SplitN(e.Response.Status, " ", 2); len(s) >= 2 {
-			reason = strings.TrimSpace(s[1])
+// SearchPullRequests returns up to limit pull requests of the project
+// matching the search qualifiers in query, along with the total number of
+// matches. It takes one GraphQL request for every 100 pull requests.
+func (client *Client) SearchPullRequests(project *Project, query string, limit int) (prs []PullRequestStatus, total int, err error) {
+	gql := `query($query: String!, $limit: Int!, $after: String) {
+		search(query: $query, type: ISSUE, first: $limit, after: $after) {
+			issueCount
+			pageInfo { hasNextPage endCursor }
+			nodes { ... on PullRequest {` + pullRequestStatusFields + ` } }
 		}
+	}`
+	variables := map[string]interface{}{
+		"query": fmt.Sprintf("repo:%s/%s is:pr %s", project.Owner, project.Name, query),
+		"after": nil,
+	}
 
-		errStr := fmt.Sprintf("Error %s: %s (HTTP %d)", action, reason, statusCode)
+	prs = []PullRequestStatus{}
+	for len(prs) < limit {
+		variables["limit"] = limit - len(prs)
+		if limit-len(prs) > 100 {
+			variables["limit"] = 100
+		}
 
-		var errorSentences []string
-		for _, err := range e.Errors {
-			switch err.Code {
-			case "custom":
-				errorSentences = append(errorSentences, err.Message)
-			case "missing_field":
-				errorSentences = append(errorSentences, fmt.Sprintf("Missing field: \"%s\"", err.Field))
-			case "already_exists":
-				errorSentences = append(errorSentences, fmt.Sprintf("Duplicate value for \"%s\"", err.Field))
-			case "invalid":
-				errorSentences = append(errorSentences, fmt.Sprintf("Invalid value for \"%s\"", err.Field))
-			case "unauthorized":
-				errorSentences = append(errorSentences, fmt.Sprintf("Not allowed to change field \"%s\"", err.Field))
-			}
+		var data struct {
+			Search struct {
+				IssueCount int `json:"issueCount"`
+				PageInfo   struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Nodes []pullRequestStatusNode `json:"nodes"`
+			} `json:"search"`
+		}
+		if err = client.GraphQL(gql, variables, &data); err != nil {
+			return nil, 0, err
 		}
 
-		var errorMessage string
-		if len(errorSentences) > 0 {
-			errorMessage = strings.Join(errorSentences, "\n")
-		} else {
-			errorMessage = e.Message
-			if action == "getting current user" && e.Message == "Resource not accessible by integration" {
-				errorMessage = errorMessage + "\nYou must specify GITHUB_USER via environment variable."
+		total = data.Search.IssueCount
+		for _, node := range data.Search.Nodes {
+			if node.Number != 0 && len(prs) < limit {
+				prs = append(prs, node.status())
 			}
 		}
+		if !data.Search.PageInfo.HasNextPage {
+			break
+		}
+		variables["after"] = data.Search.PageInfo.EndCursor
+	}
+	return prs, total, nil
+}
 
-		if errorMessage != "" {
-			errStr = fmt.Sprintf("%s\n%s", errStr, errorMessage)
+// FetchPullRequestStatus returns the status of a single pull request, with
+// the same fields as SearchPullRequests.
+func (client *Client) FetchPullRequestStatus(project *Project, number int) (*PullRequestStatus, error) {
+	gql := `query($owner: String!, $name: String!, $number: Int!) {
+		repository(owner: $owner, name: $name) {
+			pullRequest(number: $number) {` + pullRequestStatusFields + ` }
 		}
+	}`
+	variables := map[string]interface{}{"owner": project.Owner, "name": project.Name, "number": number}
 
-		ee = fmt.Errorf(errStr)
+	var data struct {
+		Repository struct {
+			PullRequest *pullRequestStatusNode `json:"pullRequest"`
+		} `json:"repository"`
+	}
+	if err := client.GraphQL(gql, variables, &data); err != nil {
+		return nil, err
+	}
+	if data.Repository.PullRequest == nil {
+		return nil, fmt.Errorf("pull request #%d of %s was not found", number, project)
+	}
+
+	status := data.Repository.PullRequest.status()
+	return &status, nil
+}
+
+func (client *Client) PullRequest(project *Project, number int) (pr *PullRequest, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.GetFile(fmt.Sprintf("repos/%s/%s/pulls/%d", project.Owner, project.Name, number), draftsType)
+	if err = checkStatus(200, "getting pull request", res, err); err != nil {
+		return
 	}
 
+	pr = &PullRequest{}
+	err = res.Unmarshal(pr)
 	return
 }
 
-func NewClientWithHost(host *Host) *Client {
-	return &Client{Host: host}
+func (client *Client) CreatePullRequest(project *Project, params map[string]interface{}) (pr *PullRequest, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.jsonRequest("POST", fmt.Sprintf("repos/%s/%s/pulls", project.Owner, project.Name), params, func(req *http.Request) {
+		// needed for fetching draft PRs
+		req.Header.Set("Accept", draftsType)
+	})
+	if err = checkStatus(201, "creating pull request", res, err); err != nil {
+		if res != nil && res.StatusCode == 404 {
+			projectUrl := strings.SplitN(project.WebURL("", "", ""), "://", 2)[1]
+			err = fmt.Errorf("%s\nAre you sure that %s exists?", err, projectUrl)
+		}
+		return
+	}
+
+	pr = &PullRequest{}
+	err = res.Unmarshal(pr)
+
+	return
 }
 
-func (client *Client) FindOrCreateToken(user, password, twoFactorCode string) (token string, err error) {
-	api := client.apiClient()
+func (client *Client) RequestReview(project *Project, prNumber int, params map[string]interface{}) (err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
 
-	if len(password) >= 40 && isToken(api, password) {
-		return password, nil
+	res, err := api.PostJSON(fmt.Sprintf("repos/%s/%s/pulls/%d/requested_reviewers", project.Owner, project.Name, prNumber), params)
+	if err = checkStatus(201, "requesting reviewer", res, err); err != nil {
+		return
 	}
 
-	params := map[string]interface{}{
-		"scopes":   []string{"repo"},
-		"note_url": OAuthAppURL,
+	res.Body.Close()
+	return
+}
+
+func (client *Client) RemoveReviewRequest(project *Project, prNumber int, params map[string]interface{}) (err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
 	}
 
-	api.PrepareRequest = func(req *http.Request) {
-		req.SetBasicAuth(user, password)
-		if twoFactorCode != "" {
-			req.Header.Set("X-GitHub-OTP", twoFactorCode)
-		}
+	res, err := api.jsonRequest("DELETE", fmt.Sprintf("repos/%s/%s/pulls/%d/requested_reviewers", project.Owner, project.Name, prNumber), params, nil)
+	if err = checkStatus(200, "removing reviewer", res, err); err != nil {
+		return
 	}
 
-	count := 1
-	maxTries := 9
-	for {
-		params["note"], err = authTokenNote(count)
-		if err != nil {
-			return
-		}
+	res.Body.Close()
+	return
+}
 
-		res, postErr := api.PostJSON("authorizations", params)
-		if postErr != nil {
-			err = postErr
-			break
+func (client *Client) UpdateIssue(project *Project, issueNumber int, params map[string]interface{}) (err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.PatchJSON(fmt.Sprintf("repos/%s/%s/issues/%d", project.Owner, project.Name, issueNumber), params)
+	if err = checkStatus(200, "updating issue", res, err); err != nil {
+		return
+	}
+
+	res.Body.Close()
+	return
+}
+
+func (client *Client) FetchMilestones(project *Project) (milestones []Milestone, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/milestones?per_page=100", project.Owner, project.Name)
+	milestones = []Milestone{}
+	for path != "" {
+		res, err := api.Get(path)
+		if err = checkStatus(200, "fetching milestones", res, err); err != nil {
+			return nil, err
 		}
+		path = res.Link("next")
 
-		if res.StatusCode == 201 {
-			auth := &AuthorizationEntry{}
-			if err = res.Unmarshal(auth); err != nil {
-				return
-			}
-			token = auth.Token
-			break
-		} else if res.StatusCode == 422 && count < maxTries {
-			count++
-		} else {
-			errInfo, e := res.ErrorInfo()
-			if e == nil {
-				err = errInfo
-			} else {
-				err = e
-			}
-			return
+		milestonesPage := []Milestone{}
+		if err = res.Unmarshal(&milestonesPage); err != nil {
+			return nil, err
 		}
+		milestones = append(milestones, milestonesPage...)
+	}
+	return
+}
+
+func (client *Client) Repository(project *Project) (repo *Repository, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
 	}
 
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s", project.Owner, project.Name))
+	if err = checkStatus(200, "getting repository info", res, err); err != nil {
+		return
+	}
+
+	repo = &Repository{}
+	err = res.Unmarshal(&repo)
 	return
 }
 
-func isToken(api *simpleClient, password string) bool {
-	api.PrepareRequest = func(req *http.Request) {
-		req.Header.Set("Authorization", "token "+password)
+// Readme returns the raw contents of the repository README, or an empty
+// string when the repository has none.
+func (client *Client) Readme(project *Project) (readme string, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
 	}
 
-	res, _ := api.Get("user")
-	if res != nil && res.StatusCode == 200 {
-		return true
+	res, err := api.GetFile(fmt.Sprintf("repos/%s/%s/readme", project.Owner, project.Name), rawMediaType)
+	if err == nil && res.StatusCode == 404 {
+		res.Body.Close()
+		return
+	}
+	if err = checkStatus(200, "getting README", res, err); err != nil {
+		return
 	}
-	return false
+	defer res.Body.Close()
+
+	content, err := ioutil.ReadAll(res.Body)
+	readme = string(content)
+	return
 }
 
-func (client *simpleClient) jsonRequest(method, path string, body interface{}, configure func(*http.Request)) (*simpleResponse, error) {
-	json, err := json.Marshal(body)
+// ForkRepository asks GitHub to fork the project into the account of the
+// authenticated user. Forking happens asynchronously, so the returned
+// repository may not be available right away.
+func (client *Client) ForkRepository(project *Project) (repo *Repository, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.PostJSON(fmt.Sprintf("repos/%s/%s/forks", project.Owner, project.Name), map[string]interface{}{})
+	if err = checkStatus(202, "creating fork", res, err); err != nil {
+		return
+	}
+
+	repo = &Repository{}
+	err = res.Unmarshal(repo)
+	return
+}
+
+type PullRequestMergeResult struct {
+	Sha     string `json:"sha"`
+	Merged  bool   `json:"merged"`
+	Message string `json:"message"`
+}
+
+func (client *Client) UpdatePullRequest(project *Project, number int, params map[string]interface{}) (pr *PullRequest, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.PatchJSON(fmt.Sprintf("repos/%s/%s/pulls/%d", project.Owner, project.Name, number), params)
+	if err = checkStatus(200, "updating pull request", res, err); err != nil {
+		return
+	}
+
+	pr = &PullRequest{}
+	err = res.Unmarshal(pr)
+	return
+}
+
+func (client *Client) MarkPullRequestReady(pr *PullRequest) error {
+	query := `mutation($id: ID!) {
+		markPullRequestReadyForReview(input: {pullRequestId: $id}) { pullRequest { isDraft } }
+	}`
+	return client.GraphQL(query, map[string]interface{}{"id": pr.NodeId}, nil)
+}
+
+func (client *Client) ConvertPullRequestToDraft(pr *PullRequest) error {
+	query := `mutation($id: ID!) {
+		convertPullRequestToDraft(input: {pullRequestId: $id}) { pullRequest { isDraft } }
+	}`
+	return client.GraphQL(query, map[string]interface{}{"id": pr.NodeId}, nil)
+}
+
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []graphQLError  `json:"errors"`
+}
+
+// graphQLPath returns the path of the GraphQL endpoint, which lives outside
+// of /api/v3 on GitHub Enterprise.
+func (client *simpleClient) graphQLPath() string {
+	if !strings.HasPrefix(client.rootUrl.Host, "api.github.") {
+		return "/api/graphql"
+	}
+	return "graphql"
+}
+
+// Request performs an arbitrary API request and returns the response as is,
+// for commands that give direct access to the API. The path "graphql" is
+// mapped to the GraphQL endpoint of the host.
+func (client *Client) Request(method, path string, body io.Reader, headers http.Header) (*http.Response, error) {
+	api, err := client.simpleApi()
 	if err != nil {
 		return nil, err
 	}
-	buf := bytes.NewBuffer(json)
 
-	return client.performRequest(method, path, buf, func(req *http.Request) {
-		req.Header.Set("Content-Type", "application/json; charset=utf-8")
-		if configure != nil {
-			configure(req)
+	if path == "graphql" {
+		path = api.graphQLPath()
+	}
+
+	res, err := api.PerformRequest(method, path, body, func(req *http.Request) {
+		for name, values := range headers {
+			req.Header[name] = values
 		}
 	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Response, nil
 }
 
-func authTokenNote(num int) (string, error) {
-	n := os.Getenv("USER")
+// GraphQL performs a query against the GraphQL API of the host and decodes
+// the "data" member of the response into data, when given.
+func (client *Client) GraphQL(query string, variables map[string]interface{}, data interface{}) (err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
 
-	if n == "" {
-		n = os.Getenv("USERNAME")
+	params := map[string]interface{}{"query": query}
+	if len(variables) > 0 {
+		params["variables"] = variables
 	}
 
-	if n == "" {
-		whoami := exec.Command("whoami")
-		whoamiOut, err := whoami.Output()
-		if err != nil {
-			return "", err
-		}
-		n = strings.TrimSpace(string(whoamiOut))
+	res, err := api.PostJSON(api.graphQLPath(), params)
+	if err = checkStatus(200, "performing GraphQL request", res, err); err != nil {
+		return
 	}
 
-	h, err := os.Hostname()
-	if err != nil {
-		return "", err
+	response := &graphQLResponse{}
+	if err = res.Unmarshal(response); err != nil {
+		return
 	}
 
-	if num > 1 {
-		return fmt.Sprintf("hub for %s@%s %d", n, h, num), nil
+	if len(response.Errors) > 0 {
+		messages := make([]string, 0, len(response.Errors))
+		for _, e := range response.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("GraphQL error: %s", strings.Join(messages, "\n"))
 	}
 
-	return fmt.Sprintf("hub for %s@%s", n, h), nil
+	if data != nil && len(response.Data) > 0 {
+		err = json.Unmarshal(response.Data, data)
+	}
+	return
 }
 
-type AuthorizationEntry struct {
-	Token string `json:"token"`
+func (client *Client) MergePullRequest(project *Project, number int, params map[string]interface{}) (result *PullRequestMergeResult, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.PutJSON(fmt.Sprintf("repos/%s/%s/pulls/%d/merge", project.Owner, project.Name, number), params)
+	if err == nil && (res.StatusCode == 405 || res.StatusCode == 409) {
+		reason := ""
+		if errInfo, e := res.ErrorInfo(); e == nil {
+			reason = errInfo.Message
+		}
+		if res.StatusCode == 405 {
+			err = fmt.Errorf("Pull request #%d is not mergeable: %s", number, reason)
+		} else {
+			err = fmt.Errorf("Pull request #%d was not merged because its head has changed: %s", number, reason)
+		}
+		return
+	}
+	if err = checkStatus(200, "merging pull request", res, err); err != nil {
+		return
+	}
+
+	result = &PullRequestMergeResult{}
+	err = res.Unmarshal(result)
+	return
+}
+
+func (client *Client) DeleteBranch(project *Project, branch string) (err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Delete(fmt.Sprintf("repos/%s/%s/git/refs/heads/%s", project.Owner, project.Name, branch))
+	if err = checkStatus(204, "deleting branch", res, err); err != nil {
+		return
+	}
+
+	res.Body.Close()
+	return
+}
+
+func (client *Client) PullRequestDiff(project *Project, number int) (io.ReadCloser, error) {
+	return client.pullRequestFile(project, number, diffMediaType)
+}
+
+func (client *Client) PullRequestPatch(project *Project, number int) (io.ReadCloser, error) {
+	return client.pullRequestFile(project, number, patchMediaType)
+}
+
+func (client *Client) pullRequestFile(project *Project, number int, mimeType string) (file io.ReadCloser, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.GetFile(fmt.Sprintf("repos/%s/%s/pulls/%d", project.Owner, project.Name, number), mimeType)
+	if err = checkStatus(200, "getting pull request diff", res, err); err != nil {
+		return
+	}
+
+	return res.Body, nil
+}
+
+func (client *Client) FetchIssues(project *Project, filterParams map[string]interface{}, limit int, filter func(*Issue) bool) (issues []Issue, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/issues?per_page=%d", project.Owner, project.Name, perPage(limit, 100))
+	if filterParams != nil {
+		path = addQuery(path, filterParams)
+	}
+
+	issues = []Issue{}
+	var res *simpleResponse
+
+	for path != "" {
+		res, err = api.Get(path)
+		if err = checkStatus(200, "fetching issues", res, err); err != nil {
+			return
+		}
+		path = res.Link("next")
+
+		issuesPage := []Issue{}
+		if err = res.Unmarshal(&issuesPage); err != nil {
+			return
+		}
+		for _, issue := range issuesPage {
+			if filter == nil || filter(&issue) {
+				issues = append(issues, issue)
+				if limit > 0 && len(issues) == limit {
+					path = ""
+					break
+				}
+			}
+		}
+	}
+
+	return
+}
+
+func (client *Client) Issue(project *Project, number int) (issue *Issue, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s/issues/%d", project.Owner, project.Name, number))
+	if err = checkStatus(200, "getting issue", res, err); err != nil {
+		return
+	}
+
+	issue = &Issue{}
+	err = res.Unmarshal(issue)
+	return
+}
+
+func (client *Client) CreateIssue(project *Project, params map[string]interface{}) (issue *Issue, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.PostJSON(fmt.Sprintf("repos/%s/%s/issues", project.Owner, project.Name), params)
+	if err = checkStatus(201, "creating issue", res, err); err != nil {
+		return
+	}
+
+	issue = &Issue{}
+	err = res.Unmarshal(issue)
+	return
+}
+
+func (client *Client) FetchPullRequestReviews(project *Project, number int) (reviews []Review, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d/reviews?per_page=100", project.Owner, project.Name, number)
+	reviews = []Review{}
+	for path != "" {
+		res, err := api.Get(path)
+		if err = checkStatus(200, "fetching pull request reviews", res, err); err != nil {
+			return nil, err
+		}
+		path = res.Link("next")
+
+		reviewsPage := []Review{}
+		if err = res.Unmarshal(&reviewsPage); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, reviewsPage...)
+	}
+	return
+}
+
+func (client *Client) CreatePullRequestReview(project *Project, number int, params map[string]interface{}) (review *Review, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.PostJSON(fmt.Sprintf("repos/%s/%s/pulls/%d/reviews", project.Owner, project.Name, number), params)
+	if err = checkStatus(200, "submitting review", res, err); err != nil {
+		return
+	}
+
+	review = &Review{}
+	err = res.Unmarshal(review)
+	return
+}
+
+func (client *Client) CreateIssueComment(project *Project, number int, body string) (comment *Comment, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	params := map[string]interface{}{"body": body}
+	res, err := api.PostJSON(fmt.Sprintf("repos/%s/%s/issues/%d/comments", project.Owner, project.Name, number), params)
+	if err = checkStatus(201, "creating comment", res, err); err != nil {
+		return
+	}
+
+	comment = &Comment{}
+	err = res.Unmarshal(comment)
+	return
+}
+
+func (client *Client) FetchIssueComments(project *Project, number int) ([]Comment, error) {
+	path := fmt.Sprintf("repos/%s/%s/issues/%d/comments?per_page=100", project.Owner, project.Name, number)
+	return client.fetchComments(path, "fetching comments")
+}
+
+func (client *Client) FetchReviewComments(project *Project, number int) ([]Comment, error) {
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d/comments?per_page=100", project.Owner, project.Name, number)
+	return client.fetchComments(path, "fetching review comments")
+}
+
+func (client *Client) fetchComments(path, action string) (comments []Comment, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	comments = []Comment{}
+	for path != "" {
+		res, err := api.Get(path)
+		if err = checkStatus(200, action, res, err); err != nil {
+			return nil, err
+		}
+		path = res.Link("next")
+
+		commentsPage := []Comment{}
+		if err = res.Unmarshal(&commentsPage); err != nil {
+			return nil, err
+		}
+		comments = append(comments, commentsPage...)
+	}
+	return
+}
+
+func (client *Client) FetchCombinedStatus(project *Project, sha string) (status *CombinedStatus, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s/commits/%s/status", project.Owner, project.Name, sha))
+	if err = checkStatus(200, "fetching commit status", res, err); err != nil {
+		return
+	}
+
+	status = &CombinedStatus{}
+	err = res.Unmarshal(status)
+	return
+}
+
+func (client *Client) FetchCheckRuns(project *Project, sha string) (checkRuns []CheckRun, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/commits/%s/check-runs?per_page=100", project.Owner, project.Name, sha)
+	checkRuns = []CheckRun{}
+	for path != "" {
+		res, err := api.GetFile(path, checksType)
+		if err = checkStatus(200, "fetching checks", res, err); err != nil {
+			return nil, err
+		}
+		path = res.Link("next")
+
+		page := checkRunsPage{}
+		if err = res.Unmarshal(&page); err != nil {
+			return nil, err
+		}
+		checkRuns = append(checkRuns, page.CheckRuns...)
+	}
+	return
+}
+
+func (client *Client) Team(org, slug string) (team *Team, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get(fmt.Sprintf("orgs/%s/teams/%s", org, slug))
+	if err = checkStatus(200, "getting team", res, err); err != nil {
+		return
+	}
+
+	team = &Team{}
+	err = res.Unmarshal(team)
+	return
+}
+
+// CreateRepository creates a repository owned by the organization, or by the
+// authenticated user when org is empty.
+func (client *Client) CreateRepository(org string, params map[string]interface{}) (repo *Repository, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	path := "user/repos"
+	if org != "" {
+		path = fmt.Sprintf("orgs/%s/repos", org)
+	}
+
+	res, err := api.PostJSON(path, params)
+	if err = checkStatus(201, "creating repository", res, err); err != nil {
+		return
+	}
+
+	repo = &Repository{}
+	err = res.Unmarshal(repo)
+	return
+}
+
+func (client *Client) FetchReleases(project *Project, limit int, filter func(*Release) bool) (releases []Release, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/releases?per_page=%d", project.Owner, project.Name, perPage(limit, 100))
+
+	releases = []Release{}
+	var res *simpleResponse
+
+	for path != "" {
+		res, err = api.Get(path)
+		if err = checkStatus(200, "fetching releases", res, err); err != nil {
+			return
+		}
+		path = res.Link("next")
+
+		releasesPage := []Release{}
+		if err = res.Unmarshal(&releasesPage); err != nil {
+			return
+		}
+		for _, release := range releasesPage {
+			if filter == nil || filter(&release) {
+				releases = append(releases, release)
+				if limit > 0 && len(releases) == limit {
+					path = ""
+					break
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// FetchRelease finds the release for a tag. Draft releases are not served
+// by the tags endpoint, so they are looked up in the list of releases.
+func (client *Client) FetchRelease(project *Project, tagName string) (release *Release, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s/releases/tags/%s", project.Owner, project.Name, url.PathEscape(tagName)))
+	if err == nil && res.StatusCode == 404 {
+		res.Body.Close()
+
+		releases, err := client.FetchReleases(project, 1, func(r *Release) bool {
+			return r.TagName == tagName
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(releases) == 0 {
+			return nil, fmt.Errorf("Unable to find release with tag name `%s'", tagName)
+		}
+		return &releases[0], nil
+	}
+	if err = checkStatus(200, "getting release", res, err); err != nil {
+		return
+	}
+
+	release = &Release{}
+	err = res.Unmarshal(release)
+	return
+}
+
+func (client *Client) LatestRelease(project *Project) (release *Release, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get(fmt.Sprintf("repos/%s/%s/releases/latest", project.Owner, project.Name))
+	if err = checkStatus(200, "getting latest release", res, err); err != nil {
+		return
+	}
+
+	release = &Release{}
+	err = res.Unmarshal(release)
+	return
+}
+
+func (client *Client) CreateRelease(project *Project, params map[string]interface{}) (release *Release, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.PostJSON(fmt.Sprintf("repos/%s/%s/releases", project.Owner, project.Name), params)
+	if err = checkStatus(201, "creating release", res, err); err != nil {
+		return
+	}
+
+	release = &Release{}
+	err = res.Unmarshal(release)
+	return
+}
+
+func (client *Client) UpdateRelease(release *Release, params map[string]interface{}) (updatedRelease *Release, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.PatchJSON(release.ApiUrl, params)
+	if err = checkStatus(200, "editing release", res, err); err != nil {
+		return
+	}
+
+	updatedRelease = &Release{}
+	err = res.Unmarshal(updatedRelease)
+	return
+}
+
+func (client *Client) DeleteRelease(release *Release) (err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Delete(release.ApiUrl)
+	if err = checkStatus(204, "deleting release", res, err); err != nil {
+		return
+	}
+
+	res.Body.Close()
+	return
+}
+
+func (client *Client) DeleteReleaseAsset(asset *ReleaseAsset) (err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Delete(asset.ApiUrl)
+	if err = checkStatus(204, "deleting release asset", res, err); err != nil {
+		return
+	}
+
+	res.Body.Close()
+	return
+}
+
+// UploadReleaseAsset streams a file to the uploads host given by the upload
+// URL of the release. The request body is the raw file, so the size and
+// content type have to be known up front.
+func (client *Client) UploadReleaseAsset(release *Release, filename, label string) (asset *ReleaseAsset, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	uploadURL, err := url.Parse(strings.SplitN(release.UploadUrl, "{", 2)[0])
+	if err != nil {
+		return
+	}
+	params := url.Values{}
+	params.Set("name", filepath.Base(filename))
+	if label != "" {
+		params.Set("label", label)
+	}
+	uploadURL.RawQuery = params.Encode()
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return
+	}
+	contentType, err := detectContentType(file)
+	if err != nil {
+		return
+	}
+
+	res, err := api.performRequestUrl("POST", uploadURL, file, func(req *http.Request) {
+		req.ContentLength = stat.Size()
+		req.Header.Set("Content-Type", contentType)
+	})
+	if err = checkStatus(201, "uploading release asset", res, err); err != nil {
+		return
+	}
+
+	asset = &ReleaseAsset{}
+	err = res.Unmarshal(asset)
+	return
+}
+
+// detectContentType guesses the type of a file from its extension, falling
+// back to sniffing its first bytes. The file is rewound afterwards.
+func detectContentType(file *os.File) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(file.Name())); contentType != "" {
+		return contentType, nil
+	}
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
+func (client *Client) DownloadReleaseAsset(asset *ReleaseAsset) (file io.ReadCloser, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.GetFile(asset.ApiUrl, "application/octet-stream")
+	if err = checkStatus(200, "downloading release asset", res, err); err != nil {
+		return
+	}
+
+	return res.Body, nil
+}
+
+func addQuery(path string, params map[string]interface{}) string {
+	if len(params) == 0 {
+		return path
+	}
+
+	query := url.Values{}
+	for key, val := range params {
+		switch v := val.(type) {
+		case string:
+			query.Add(key, v)
+		case nil:
+			query.Add(key, "")
+		case int:
+			query.Add(key, fmt.Sprintf("%d", v))
+		case bool:
+			query.Add(key, fmt.Sprintf("%v", v))
+		}
+	}
+	sep := "?"
+	if strings.Contains(path, sep) {
+		sep = "&"
+	}
+	return path + sep + query.Encode()
+}
+
+func perPage(limit, max int) int {
+	if limit > 0 {
+		limit = limit + (limit / 2)
+		if limit < max {
+			return limit
+		}
+	}
+	return max
+}
+
+func checkStatus(expectedStatus int, action string, response *simpleResponse, err error) error {
+	if err != nil {
+		return fmt.Errorf("error %s: %s", action, err.Error())
+	} else if response.StatusCode != expectedStatus {
+		errInfo, err := response.ErrorInfo()
+		if err == nil {
+			return FormatError(action, errInfo)
+		} else {
+			return fmt.Errorf("error %s: %s (HTTP %d)", action, err.Error(), response.StatusCode)
+		}
+	} else {
+		return nil
+	}
+}
+
+func FormatError(action string, err error) (ee error) {
+	switch e := err.(type) {
+	default:
+		ee = err
+	case *errorInfo:
+		statusCode := e.Response.StatusCode
+		var reason string
+		if s := strings.SplitN(e.Response.Status, " ", 2); len(s) >= 2 {
+			reason = strings.TrimSpace(s[1])
+		}
+
+		errStr := fmt.Sprintf("Error %s: %s (HTTP %d)", action, reason, statusCode)
+
+		var errorSentences []string
+		for _, err := range e.Errors {
+			switch err.Code {
+			case "custom":
+				errorSentences = append(errorSentences, err.Message)
+			case "missing_field":
+				errorSentences = append(errorSentences, fmt.Sprintf("Missing field: \"%s\"", err.Field))
+			case "already_exists":
+				errorSentences = append(errorSentences, fmt.Sprintf("Duplicate value for \"%s\"", err.Field))
+			case "invalid":
+				errorSentences = append(errorSentences, fmt.Sprintf("Invalid value for \"%s\"", err.Field))
+			case "unauthorized":
+				errorSentences = append(errorSentences, fmt.Sprintf("Not allowed to change field \"%s\"", err.Field))
+			}
+		}
+
+		var errorMessage string
+		if len(errorSentences) > 0 {
+			errorMessage = strings.Join(errorSentences, "\n")
+		} else {
+			errorMessage = e.Message
+			if action == "getting current user" && e.Message == "Resource not accessible by integration" {
+				errorMessage = errorMessage + "\nYou must specify GITHUB_USER via environment variable."
+			}
+		}
+
+		if errorMessage != "" {
+			errStr = fmt.Sprintf("%s\n%s", errStr, errorMessage)
+		}
+
+		ee = fmt.Errorf(errStr)
+	}
+
+	return
+}
+
+func NewClientWithHost(host *Host) *Client {
+	return &Client{Host: host}
+}
+
+func (client *simpleClient) jsonRequest(method, path string, body interface{}, configure func(*http.Request)) (*simpleResponse, error) {
+	json, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(json)
+
+	return client.PerformRequest(method, path, buf, func(req *http.Request) {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		if configure != nil {
+			configure(req)
+		}
+	})
+}
+
+
+// CurrentUserScopes returns the user that the access token belongs to along
+// with the OAuth scopes granted to the token, as listed in X-OAuth-Scopes.
+func (client *Client) CurrentUserScopes() (user *User, scopes []string, err error) {
+	api, err := client.simpleApi()
+	if err != nil {
+		return
+	}
+
+	res, err := api.Get("user")
+	if err = checkStatus(200, "getting current user", res, err); err != nil {
+		return
+	}
+
+	scopes = []string{}
+	for _, scope := range strings.Split(res.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	user = &User{}
+	err = res.Unmarshal(user)
+	return
 }
 
 func (client *Client) CurrentUser() (user *User, err error) {
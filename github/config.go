@@ -4,34 +4,67 @@ import (
 	"bufio"
 	"fmt"
 	"github.com/mitchellh/go-homedir"
+	"github.com/npathai/github-cli-clone/git"
 	"github.com/npathai/github-cli-clone/ui"
 	"github.com/npathai/github-cli-clone/utils"
-	"golang.org/x/crypto/ssh/terminal"
+	"io/ioutil"
 	"net/url"
 	"os"
-	"os/signal"
 	"path/filepath"
+	"reflect"
 	"strings"
-	"syscall"
 )
 
 type Host struct {
 	Host        string `toml:"host"`
 	User        string `toml:"user"`
-	AccessToken string `toml:"access_token"`
+	AccessToken string `toml:"access_token,omitempty"`
 	Protocol    string `toml:"protocol"`
 	UnixSocket  string `toml:"unix_socket,omitempty"`
+
+	OAuthClientID string `toml:"oauth_client_id,omitempty"`
+
+	// CredentialStore names the store holding AccessToken when the token is
+	// not kept in the configuration file itself.
+	CredentialStore string `toml:"credential_store,omitempty"`
+
+	// Active marks the account used for the host when several are configured.
+	Active bool `toml:"active,omitempty"`
+}
+
+// IsSSH reports whether git remotes for the host should use SSH URLs. This
+// is the case when its protocol is configured as "ssh".
+func (h *Host) IsSSH() bool {
+	return h != nil && h.Protocol == "ssh"
+}
+
+// webProtocol returns the configured protocol when it can be used to talk
+// to the web and API endpoints of the host.
+func (h *Host) webProtocol() string {
+	if h != nil && (h.Protocol == "http" || h.Protocol == "https") {
+		return h.Protocol
+	}
+	return ""
 }
 
 type yamlHost struct {
 	User       string `yaml:"user"`
-	OAuthToken string `yaml:"oauth_token"`
+	OAuthToken string `yaml:"oauth_token,omitempty"`
 	Protocol   string `yaml:"protocol"`
 	UnixSocket string `yaml:"unix_socket,omitempty"`
+
+	OAuthClientID   string `yaml:"oauth_client_id,omitempty"`
+	CredentialStore string `yaml:"credential_store,omitempty"`
+	Active          bool   `yaml:"active,omitempty"`
 }
 
+// Config holds the accounts of every configured host. A host may appear
+// several times, once for each account, with one of them marked Active.
 type Config struct {
 	Hosts []*Host `toml:"hosts"`
+
+	repoUser       string
+	repoUserLoaded bool
 }
 
 var currentConfig *Config
@@ -41,7 +74,14 @@ func CurrentConfig() *Config {
 	filename := configsFile()
 	if configLoadedFrom != filename {
 		currentConfig =	&Config{}
-		newConfigService().Load(filename, currentConfig)
+		service := newConfigService()
+		if service.Load(filename, currentConfig) == nil && currentConfig.hasPlaintextTokens() {
+			// Move tokens saved by older versions into the credential store.
+			// Failures leave the file untouched and are retried next time.
+			if store, err := service.DefaultStore(); err == nil && store != nil {
+				service.Save(filename, currentConfig)
+			}
+		}
 		configLoadedFrom = filename
 	}
 
@@ -110,8 +150,9 @@ func determineConfigLocation() (string, error) {
 }
 
 func (config *Config) PromptForHost(host string) (h *Host, err error) {
-	token := config.DetectToken()
+	token, _ := config.DetectToken(host)
 	tokenFromEnv := token != ""
+	reauthorizedUser := ""
 
 	if host != GitHubHost {
 		if _, e := url.Parse("https://" + host); e != nil {
@@ -136,9 +177,16 @@ func (config *Config) PromptForHost(host string) (h *Host, err error) {
 			utils.Check(err)
 		}
 		if tokenFromEnv {
-			h.AccessToken = token
-		} else {
+			// Use a copy so that the token from the environment is never saved.
+			account := *h
+			account.AccessToken = token
+			h = &account
+		} else if h.AccessToken != "" {
 			return
+		} else {
+			// The token could not be read back from the credential store.
+			ui.Errorf("The token of %s on %s is missing; please authorize again.\n", h.User, host)
+			reauthorizedUser = h.User
 		}
 	} else {
 		h = &Host{
@@ -146,7 +194,10 @@ func (config *Config) PromptForHost(host string) (h *Host, err error) {
 			AccessToken: token,
 			Protocol:    "https",
 		}
-		config.Hosts = append(config.Hosts, h)
+		// A host using the token from the environment stays temporary.
+		if !tokenFromEnv {
+			config.Hosts = append(config.Hosts, h)
+		}
 	}
 
 	client := NewClientWithHost(h)
@@ -175,6 +226,10 @@ func (config *Config) PromptForHost(host string) (h *Host, err error) {
 		if err != nil {
 			return
 		}
+		if reauthorizedUser != "" && !strings.EqualFold(currentUser.Login, reauthorizedUser) {
+			err = fmt.Errorf("authorized as %s instead of %s; run `gh auth login` to add %s as another account", currentUser.Login, reauthorizedUser, currentUser.Login)
+			return
+		}
 		h.User = currentUser.Login
 	}
 
@@ -185,13 +240,71 @@ func (config *Config) PromptForHost(host string) (h *Host, err error) {
 	return
 }
 
-func (c *Config) DetectToken() string {
-	return os.Getenv("GITHUB_TOKEN")
+var (
+	gitHubTokenVars     = []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	enterpriseTokenVars = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+)
+
+// DetectToken returns the token set in the environment for the host along
+// with the name of the variable it was read from. GH_TOKEN and GITHUB_TOKEN
+// only apply to github.com, while GH_ENTERPRISE_TOKEN and
+// GITHUB_ENTERPRISE_TOKEN apply to every other host, so that a github.com
+// token is never sent to an Enterprise server.
+func (c *Config) DetectToken(host string) (token, source string) {
+	vars := enterpriseTokenVars
+	if host == GitHubHost {
+		vars = gitHubTokenVars
+	}
+	for _, name := range vars {
+		if token := os.Getenv(name); token != "" {
+			return token, name
+		}
+	}
+	return "", ""
 }
 
+// Find returns the account to use for the host. The user set with `git config
+// gh.user` is picked when it has an account on the host, so that a repository
+// can be tied to an account; otherwise the active account is returned.
 func (c *Config) Find(host string) *Host {
+	if user := c.RepositoryUser(); user != "" {
+		if h := c.FindUser(host, user); h != nil {
+			return h
+		}
+	}
+	return c.ActiveAccount(host)
+}
+
+// ActiveAccount returns the account marked active for the host, falling back
+// to the first account when none is.
+func (c *Config) ActiveAccount(host string) *Host {
+	accounts := c.Accounts(host)
+	for _, h := range accounts {
+		if h.Active {
+			return h
+		}
+	}
+	if len(accounts) > 0 {
+		return accounts[0]
+	}
+
+	return nil
+}
+
+// Accounts returns every account configured for the host.
+func (c *Config) Accounts(host string) []*Host {
+	accounts := []*Host{}
 	for _, h := range c.Hosts {
 		if h.Host == host {
+			accounts = append(accounts, h)
+		}
+	}
+	return accounts
+}
+
+func (c *Config) FindUser(host, user string) *Host {
+	for _, h := range c.Hosts {
+		if h.Host == host && strings.EqualFold(h.User, user) {
 			return h
 		}
 	}
@@ -199,6 +312,126 @@ func (c *Config) Find(host string) *Host {
 	return nil
 }
 
+// RepositoryUser returns the account override set with `git config gh.user`.
+func (c *Config) RepositoryUser() string {
+	if !c.repoUserLoaded {
+		c.repoUser, _ = git.Config("gh.user")
+		c.repoUserLoaded = true
+	}
+	return c.repoUser
+}
+
+// AddAccount stores the account and makes it the active one of its host. An
+// existing account of the same user is replaced.
+func (c *Config) AddAccount(account *Host) {
+	replaced := false
+	for i, h := range c.Hosts {
+		if h.Host == account.Host && strings.EqualFold(h.User, account.User) {
+			c.Hosts[i] = account
+			replaced = true
+		}
+	}
+	if !replaced {
+		c.Hosts = append(c.Hosts, account)
+	}
+	c.Switch(account.Host, account.User)
+}
+
+// Switch marks the account of user as the active one of the host.
+func (c *Config) Switch(host, user string) error {
+	account := c.FindUser(host, user)
+	if account == nil {
+		return fmt.Errorf("no account %s found for %s", user, host)
+	}
+	for _, h := range c.Accounts(host) {
+		h.Active = h == account
+	}
+	return nil
+}
+
+// Remove drops the account of user on the host, deleting its token from the
+// credential store, and returns it, or nil when there is no such account.
+// When the active account is removed, the first remaining one takes over.
+func (c *Config) Remove(host, user string) *Host {
+	for i, h := range c.Hosts {
+		if h.Host == host && strings.EqualFold(h.User, user) {
+			c.Hosts = append(c.Hosts[:i], c.Hosts[i+1:]...)
+			if store := credentialStoreNamed(h.CredentialStore); store != nil {
+				store.Delete(h.Host, h.User)
+			}
+			if remaining := c.Accounts(host); h.Active && len(remaining) > 0 {
+				c.Switch(host, remaining[0].User)
+			}
+			return h
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) hasPlaintextTokens() bool {
+	for _, h := range c.Hosts {
+		if h.AccessToken != "" && h.CredentialStore == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Save writes the configuration back to the configuration file.
+func (c *Config) Save() error {
+	if err := CheckWriteable(configsFile()); err != nil {
+		return err
+	}
+	return newConfigService().Save(configsFile(), c)
+}
+
+// ConfigFile returns the path of the configuration file.
+func ConfigFile() string {
+	return configsFile()
+}
+
+// ConfigFileFormat returns the format the configuration file is written in.
+func ConfigFileFormat() *ConfigFormat {
+	return detectConfigFormat(configsFile())
+}
+
+// MigrateConfig rewrites the configuration file in format. The file is read
+// back afterwards and restored when any host did not survive the conversion.
+func MigrateConfig(format *ConfigFormat) error {
+	filename := configsFile()
+	original, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no configuration found at %s", filename)
+	} else if err != nil {
+		return err
+	}
+	if err := CheckWriteable(filename); err != nil {
+		return err
+	}
+
+	c := &Config{}
+	if err := newConfigService().Load(filename, c); err != nil {
+		return err
+	}
+	service := newConfigService()
+	service.Format = format
+	if err := service.Save(filename, c); err != nil {
+		return err
+	}
+
+	migrated := &Config{}
+	err = newConfigService().Load(filename, migrated)
+	if err == nil && !reflect.DeepEqual(migrated.Hosts, c.Hosts) {
+		err = fmt.Errorf("the configuration did not convert to %s without loss", strings.ToUpper(format.Name))
+	}
+	if err != nil {
+		ioutil.WriteFile(filename, original, 0600)
+		return err
+	}
+	return nil
+}
+
 func CheckWriteable(filename string) error {
 	// Check if file exists already. if it doesn't, we will delete it after
 	// checking for writeabilty
@@ -251,84 +484,43 @@ func (c *Config) scanLine() string {
 	return line
 }
 
-func (config *Config) authorizeClient(client *Client, host string) (err error) {
-	user := config.PromptForUser(host)
-	pass := config.PromptForPassword(host, user)
-
-	var code, token string
-	for {
-		token, err = client.FindOrCreateToken(user, pass, code)
-		if err == nil {
-			break
-		}
-
-		if ae, ok := err.(*errorInfo); ok && strings.HasPrefix(ae.Response.Header.Get("X-GitHub-OTP"), "required;") {
-			if code != "" {
-				ui.Errorln("warning: invalid two-factor code")
-			}
-			code = config.PromptForOTP()
-		} else {
-			break
-		}
+// Authorize obtains a token for a new account on the host through the device
+// flow and fills in the user that the token belongs to.
+func (config *Config) Authorize(h *Host) error {
+	client := NewClientWithHost(h)
+	if err := config.authorizeClient(client, h.Host); err != nil {
+		return err
 	}
 
-	if err == nil {
-		client.Host.AccessToken = token
+	user, err := client.CurrentUser()
+	if err != nil {
+		return err
 	}
-
-	return
+	h.User = user.Login
+	return nil
 }
 
-func (c *Config) PromptForPassword(host, user string) (pass string) {
-	pass = os.Getenv("GITHUB_PASSWORD")
-	if pass != "" {
+// authorizeClient obtains a token for the client through the OAuth device
+// flow: the user confirms a one-time code in the browser while we poll for
+// the token.
+func (config *Config) authorizeClient(client *Client, host string) (err error) {
+	flow, err := newDeviceFlow(client.Host)
+	if err != nil {
 		return
 	}
-
-	ui.Printf("%s password for %s (never stored): ", host, user)
-	if ui.IsTerminal(os.Stdin) {
-		if password, err := getPassword(); err == nil {
-			pass = password
-		}
-	} else {
-		pass = c.scanLine()
-	}
-
-	return
-}
-
-func (c *Config) PromptForOTP() string {
-	fmt.Print("two-factor authentication code: ")
-	return c.scanLine()
-}
-
-func getPassword() (string, error) {
-	stdin := int(syscall.Stdin)
-	initialTermState, err := terminal.GetState(stdin)
+	code, err := flow.RequestCode()
 	if err != nil {
-		return "", err
+		return
 	}
 
-	c := make(chan os.Signal)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		s := <-c
-		terminal.Restore(stdin, initialTermState)
-		switch sig := s.(type) {
-		case syscall.Signal:
-			if int(sig) == 2 {
-				fmt.Println("^C")
-			}
-		}
-		os.Exit(1)
-	}()
+	ui.Errorf("First copy your one-time code: %s\n", ui.Bold(code.UserCode))
+	ui.Errorf("Then open %s in your browser and enter the code to authorize this device.\n", code.VerificationURI)
 
-	passBytes, err := terminal.ReadPassword(stdin)
+	token, err := flow.PollToken(code)
 	if err != nil {
-		return "", err
+		return
 	}
 
-	signal.Stop(c)
-	fmt.Print("\n")
-	return string(passBytes), nil
-}
\ No newline at end of file
+	client.Host.AccessToken = token
+	return
+}
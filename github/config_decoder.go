@@ -1,6 +1,7 @@
 package github
 
 import (
+	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v2"
 	"io"
 	"io/ioutil"
@@ -27,25 +28,39 @@ func (y *yamlConfigDecoder) Decode(r io.Reader, c *Config) error {
 	}
 
 	for _, hostEntry := range yc {
-		v := hostEntry.Value.([]interface{})
-		if len(v) < 1 {
-			continue
-		}
-		host := &Host{Host: hostEntry.Key.(string)}
-		for _, prop := range v[0].(yaml.MapSlice) {
-			switch prop.Key.(string) {
-			case "user":
-				host.User = prop.Value.(string)
-			case "oauth_token":
-				host.AccessToken = prop.Value.(string)
-			case "protocol":
-				host.Protocol = prop.Value.(string)
-			case "unix_socket":
-				host.UnixSocket = prop.Value.(string)
+		for _, account := range hostEntry.Value.([]interface{}) {
+			host := &Host{Host: hostEntry.Key.(string)}
+			for _, prop := range account.(yaml.MapSlice) {
+				switch prop.Key.(string) {
+				case "user":
+					host.User = prop.Value.(string)
+				case "oauth_token":
+					host.AccessToken = prop.Value.(string)
+				case "protocol":
+					host.Protocol = prop.Value.(string)
+				case "unix_socket":
+					host.UnixSocket = prop.Value.(string)
+				case "oauth_client_id":
+					host.OAuthClientID = prop.Value.(string)
+				case "credential_store":
+					host.CredentialStore = prop.Value.(string)
+				case "active":
+					host.Active = prop.Value.(bool)
+				}
 			}
+			c.Hosts = append(c.Hosts, host)
 		}
-		c.Hosts = append(c.Hosts, host)
 	}
 
 	return nil
 }
+
+// tomlConfigDecoder reads [[hosts]] tables keyed by the toml tags of Host.
+// Unknown keys are ignored.
+type tomlConfigDecoder struct {
+}
+
+func (t *tomlConfigDecoder) Decode(r io.Reader, c *Config) error {
+	_, err := toml.DecodeReader(r, c)
+	return err
+}
@@ -1,6 +1,7 @@
 package github
 
 import (
+	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v2"
 	"io"
 )
@@ -14,17 +15,27 @@ type yamlConfigEncoder struct {
 
 func (enc *yamlConfigEncoder) Encode(w io.Writer, c *Config) error {
 	yc := yaml.MapSlice{}
+	accounts := map[string]int{}
 	for _, h := range c.Hosts {
+		entry := yamlHost{
+			User:       h.User,
+			OAuthToken: h.AccessToken,
+			Protocol:   h.Protocol,
+			UnixSocket: h.UnixSocket,
+
+			OAuthClientID:   h.OAuthClientID,
+			CredentialStore: h.CredentialStore,
+			Active:          h.Active,
+		}
+
+		if i, ok := accounts[h.Host]; ok {
+			yc[i].Value = append(yc[i].Value.([]yamlHost), entry)
+			continue
+		}
+		accounts[h.Host] = len(yc)
 		yc = append(yc, yaml.MapItem{
-			Key: h.Host,
-			Value: []yamlHost{
-				{
-					User:       h.User,
-					OAuthToken: h.AccessToken,
-					Protocol:   h.Protocol,
-					UnixSocket: h.UnixSocket,
-				},
-			},
+			Key:   h.Host,
+			Value: []yamlHost{entry},
 		})
 	}
 
@@ -40,3 +51,12 @@ func (enc *yamlConfigEncoder) Encode(w io.Writer, c *Config) error {
 
 	return err
 }
+
+// tomlConfigEncoder writes every host as a [[hosts]] table, with the keys
+// taken from the toml tags of Host.
+type tomlConfigEncoder struct {
+}
+
+func (enc *tomlConfigEncoder) Encode(w io.Writer, c *Config) error {
+	return toml.NewEncoder(w).Encode(c)
+}
@@ -1,33 +1,147 @@
 package github
 
 import (
+	"fmt"
+	"github.com/npathai/github-cli-clone/ui"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 )
 
+// ConfigFormat is a file format the configuration can be written in.
+type ConfigFormat struct {
+	Name    string
+	Encoder configEncoder
+	Decoder configDecoder
+}
+
+var (
+	yamlConfigFormat = &ConfigFormat{Name: "yaml", Encoder: &yamlConfigEncoder{}, Decoder: &yamlConfigDecoder{}}
+	tomlConfigFormat = &ConfigFormat{Name: "toml", Encoder: &tomlConfigEncoder{}, Decoder: &tomlConfigDecoder{}}
+)
+
+// ConfigFormatNamed returns the format called "yaml" or "toml".
+func ConfigFormatNamed(name string) (*ConfigFormat, error) {
+	switch strings.ToLower(name) {
+	case "yaml", "yml":
+		return yamlConfigFormat, nil
+	case "toml":
+		return tomlConfigFormat, nil
+	}
+	return nil, fmt.Errorf("unknown config format %q; use yaml or toml", name)
+}
+
+// detectConfigFormat tells the format of an existing file from its content,
+// since the default location has no extension. New and empty files go by
+// their extension, defaulting to YAML.
+func detectConfigFormat(filename string) *ConfigFormat {
+	if data, err := ioutil.ReadFile(filename); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if strings.HasPrefix(line, "[") || tomlKeyPattern.MatchString(line) {
+				return tomlConfigFormat
+			}
+			return yamlConfigFormat
+		}
+	}
+
+	if strings.EqualFold(filepath.Ext(filename), ".toml") {
+		return tomlConfigFormat
+	}
+	return yamlConfigFormat
+}
+
+var tomlKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+\s*=`)
+
+// newConfigService returns a service that reads and writes files in the
+// format they are already in.
 func newConfigService() *configService {
 	return &configService{
-		Encoder: &yamlConfigEncoder{},
-		Decoder: &yamlConfigDecoder{},
+		DefaultStore: defaultCredentialStore,
 	}
 }
 
 type configService struct {
-	Encoder configEncoder
-	Decoder configDecoder
+	// Format forces the format files are written in instead of detecting it.
+	Format       *ConfigFormat
+	DefaultStore func() (CredentialStore, error)
+}
+
+func (service *configService) formatFor(filename string) *ConfigFormat {
+	if service.Format != nil {
+		return service.Format
+	}
+	return detectConfigFormat(filename)
 }
 
 func (service *configService) Load(filename string, config *Config) error {
+	format := detectConfigFormat(filename)
 	r, err := os.Open(filename)
 	if err != nil {
 		return err
 	}
 	defer r.Close()
 
-	return service.Decoder.Decode(r, config)
+	if err := format.Decoder.Decode(r, config); err != nil {
+		return fmt.Errorf("error reading %s as %s: %s", filename, strings.ToUpper(format.Name), err)
+	}
+
+	// A token that cannot be read back is reported and left empty, so that
+	// the user is asked to authenticate again.
+	for _, h := range config.Hosts {
+		if h.AccessToken != "" || h.CredentialStore == "" {
+			continue
+		}
+		store := credentialStoreNamed(h.CredentialStore)
+		if store == nil {
+			ui.Errorf("Warning: unknown credential store %q for %s on %s in %s\n", h.CredentialStore, h.User, h.Host, filename)
+			continue
+		}
+		token, err := store.Get(h.Host, h.User)
+		if err != nil {
+			ui.Errorf("Warning: unable to read the token of %s on %s from the %s credential store: %s\n", h.User, h.Host, store.Name(), err)
+			continue
+		}
+		h.AccessToken = token
+	}
+	return nil
 }
 
+// Save writes the configuration with the access tokens moved into the
+// credential store, keeping only the name of the store in the file. Tokens
+// are stored before the file is touched, so a failing store leaves the
+// previous configuration intact.
 func (s *configService) Save(filename string, c *Config) error {
+	saved := &Config{}
+	for _, h := range c.Hosts {
+		entry := *h
+		if entry.AccessToken != "" {
+			store, err := s.storeFor(&entry)
+			if err != nil {
+				return err
+			}
+			if store != nil {
+				if err := store.Set(entry.Host, entry.User, entry.AccessToken); err != nil {
+					return fmt.Errorf("%s\nSet HUB_CREDENTIAL_STORE to pick another credential store.", err)
+				}
+				h.CredentialStore = store.Name()
+				entry.CredentialStore = store.Name()
+				entry.AccessToken = ""
+			} else {
+				h.CredentialStore = ""
+				entry.CredentialStore = ""
+			}
+		}
+		saved.Hosts = append(saved.Hosts, &entry)
+	}
+
+	// Detect the format before the file is truncated.
+	format := s.formatFor(filename)
 	err := os.MkdirAll(filepath.Dir(filename), 0771)
 	if err != nil {
 		return err
@@ -39,5 +153,14 @@ func (s *configService) Save(filename string, c *Config) error {
 	}
 	defer w.Close()
 
-	return s.Encoder.Encode(w, c)
-}
\ No newline at end of file
+	return format.Encoder.Encode(w, saved)
+}
+
+// storeFor returns the store already holding the token of the host, or the
+// default store for hosts whose token is still kept in plain text.
+func (s *configService) storeFor(h *Host) (CredentialStore, error) {
+	if store := credentialStoreNamed(h.CredentialStore); store != nil {
+		return store, nil
+	}
+	return s.DefaultStore()
+}
@@ -0,0 +1,179 @@
+package github
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var roundTripHosts = []*Host{
+	{
+		Host:            "github.com",
+		User:            "me",
+		AccessToken:     `tok"en#with = 'quotes' and \ slashes`,
+		Protocol:        "https",
+		UnixSocket:      "$HOME/sockets/gh\tsocket",
+		OAuthClientID:   "client # not a comment",
+		CredentialStore: "plaintext",
+		Active:          true,
+	},
+	{
+		Host:     "github.com",
+		User:     "ünïcødé-用户",
+		Protocol: "ssh",
+	},
+	{
+		Host:        "ghe.example.com",
+		User:        "line\nbreak",
+		AccessToken: "[[hosts]]",
+		Protocol:    "http",
+	},
+}
+
+func encodeConfig(t *testing.T, format *ConfigFormat, c *Config) []byte {
+	var buf bytes.Buffer
+	if err := format.Encoder.Encode(&buf, c); err != nil {
+		t.Fatalf("encoding %s: %s", format.Name, err)
+	}
+	return buf.Bytes()
+}
+
+func decodeConfig(t *testing.T, format *ConfigFormat, data []byte) *Config {
+	c := &Config{}
+	if err := format.Decoder.Decode(bytes.NewReader(data), c); err != nil {
+		t.Fatalf("decoding %s: %s\n%s", format.Name, err, data)
+	}
+	return c
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	yamlData := encodeConfig(t, yamlConfigFormat, &Config{Hosts: roundTripHosts})
+	fromYAML := decodeConfig(t, yamlConfigFormat, yamlData)
+	if !reflect.DeepEqual(fromYAML.Hosts, roundTripHosts) {
+		t.Fatalf("YAML round trip = %+v, want %+v", fromYAML.Hosts, roundTripHosts)
+	}
+
+	tomlData := encodeConfig(t, tomlConfigFormat, fromYAML)
+	fromTOML := decodeConfig(t, tomlConfigFormat, tomlData)
+	if !reflect.DeepEqual(fromTOML.Hosts, roundTripHosts) {
+		t.Fatalf("TOML round trip = %+v, want %+v\n%s", fromTOML.Hosts, roundTripHosts, tomlData)
+	}
+
+	if again := encodeConfig(t, yamlConfigFormat, fromTOML); !bytes.Equal(again, yamlData) {
+		t.Errorf("YAML after TOML =\n%s\nwant\n%s", again, yamlData)
+	}
+}
+
+func TestTomlConfigEncoderOmitsEmptyKeys(t *testing.T) {
+	data := encodeConfig(t, tomlConfigFormat, &Config{Hosts: []*Host{{Host: "github.com", User: "me", Protocol: "https"}}})
+	want := "[[hosts]]\n  host = \"github.com\"\n  user = \"me\"\n  protocol = \"https\"\n"
+	if string(data) != want {
+		t.Errorf("Encode() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestTomlConfigDecoder(t *testing.T) {
+	data := `# written by hand
+[[hosts]]
+host = "github.com" # trailing comment
+user = 'me'
+protocol = "https"
+active = true
+unknown_key = 42
+
+[[hosts]]
+  host = "ghe.example.com"
+  user = "you"
+  protocol = "ssh"
+`
+	c := decodeConfig(t, tomlConfigFormat, []byte(data))
+	want := []*Host{
+		{Host: "github.com", User: "me", Protocol: "https", Active: true},
+		{Host: "ghe.example.com", User: "you", Protocol: "ssh"},
+	}
+	if !reflect.DeepEqual(c.Hosts, want) {
+		t.Errorf("Decode() = %+v, want %+v", c.Hosts, want)
+	}
+
+	for _, invalid := range []string{"[[hosts]]\nactive = \"yes\"\n", "[[hosts]]\nuser = \"unterminated\n"} {
+		if err := tomlConfigFormat.Decoder.Decode(strings.NewReader(invalid), &Config{}); err == nil {
+			t.Errorf("Decode(%q) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestDetectConfigFormat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gh-format")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    *ConfigFormat
+	}{
+		{"YAML content", "hub", "github.com:\n- user: me\n", yamlConfigFormat},
+		{"TOML content", "hub", "[[hosts]]\n  host = \"github.com\"\n", tomlConfigFormat},
+		{"TOML after comments", "hub", "# hosts\n\nhost = \"github.com\"\n", tomlConfigFormat},
+		{"content wins over the extension", "hub.toml", "github.com:\n- user: me\n", yamlConfigFormat},
+		{"empty file with .toml extension", "empty.toml", "", tomlConfigFormat},
+		{"missing file with .toml extension", "missing.TOML", "-", tomlConfigFormat},
+		{"missing file", "missing", "-", yamlConfigFormat},
+	}
+
+	for _, tt := range tests {
+		filename := filepath.Join(dir, tt.file)
+		if tt.content != "-" {
+			if err := ioutil.WriteFile(filename, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := detectConfigFormat(filename); got != tt.want {
+			t.Errorf("%s: detectConfigFormat() = %s, want %s", tt.name, got.Name, tt.want.Name)
+		}
+	}
+}
+
+func TestMigrateConfig(t *testing.T) {
+	original := "github.com:\n- user: me\n  oauth_token: a#b\n  protocol: https\n  active: true\n- user: you\n  oauth_token: tok\n  protocol: ssh\n"
+	defer useConfig(t, original)()
+
+	if err := MigrateConfig(tomlConfigFormat); err != nil {
+		t.Fatalf("MigrateConfig(toml) error: %s", err)
+	}
+	data, _ := ioutil.ReadFile(configsFile())
+	if ConfigFileFormat() != tomlConfigFormat || !strings.HasPrefix(string(data), "[[hosts]]") {
+		t.Fatalf("migrated file is not TOML:\n%s", data)
+	}
+
+	if err := MigrateConfig(yamlConfigFormat); err != nil {
+		t.Fatalf("MigrateConfig(yaml) error: %s", err)
+	}
+	if data, _ := ioutil.ReadFile(configsFile()); string(data) != original {
+		t.Errorf("migrating back gave\n%s\nwant\n%s", data, original)
+	}
+}
+
+func TestMigrateConfigKeepsUnreadableFiles(t *testing.T) {
+	malformed := "[[hosts]]\nuser = \"unterminated\n"
+	defer useConfig(t, malformed)()
+
+	if err := MigrateConfig(yamlConfigFormat); err == nil {
+		t.Error("MigrateConfig() of a malformed file succeeded, want an error")
+	}
+	if data, _ := ioutil.ReadFile(configsFile()); string(data) != malformed {
+		t.Errorf("the malformed file was changed to\n%s", data)
+	}
+
+	os.Remove(configsFile())
+	if err := MigrateConfig(tomlConfigFormat); err == nil || !strings.Contains(err.Error(), "no configuration found") {
+		t.Errorf("MigrateConfig() of a missing file error = %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package github
+
+import (
+	"bytes"
+	"github.com/npathai/github-cli-clone/ui"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureUI collects everything printed through ui and returns a function
+// restoring the previous output.
+func captureUI(out, errOut *bytes.Buffer) func() {
+	previous := ui.Default
+	ui.Default = ui.Console{Stdout: out, Stderr: errOut}
+	return func() { ui.Default = previous }
+}
+
+func TestLoadReportsCredentialStoreErrors(t *testing.T) {
+	defer useConfig(t, "github.com:\n- user: me\n  protocol: https\n  credential_store: file\n")()
+	credentials := filepath.Join(filepath.Dir(configsFile()), "hub-credentials")
+	if err := ioutil.WriteFile(credentials, []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errOut bytes.Buffer
+	defer captureUI(&out, &errOut)()
+
+	c := &Config{}
+	if err := newConfigService().Load(configsFile(), c); err != nil {
+		t.Fatalf("Load() error: %s", err)
+	}
+	if len(c.Hosts) != 1 || c.Hosts[0].AccessToken != "" {
+		t.Fatalf("Load() hosts = %+v, want one account without a token", c.Hosts)
+	}
+	if !strings.Contains(errOut.String(), "unable to read the token of me on github.com from the file credential store") || !strings.Contains(errOut.String(), "is corrupt") {
+		t.Errorf("Load() reported %q, want the store error", errOut.String())
+	}
+}
+
+func TestPromptForHostAuthorizesAccountsWithoutToken(t *testing.T) {
+	defer useConfig(t, "ghe.example.com:\n- user: me\n  protocol: https\n  credential_store: file\n")()
+	defer setEnv(map[string]string{"GH_ENTERPRISE_TOKEN": "", "GITHUB_ENTERPRISE_TOKEN": "", OAuthClientIDEnv: ""})()
+
+	var out, errOut bytes.Buffer
+	defer captureUI(&out, &errOut)()
+
+	c := &Config{}
+	if err := newConfigService().Load(configsFile(), c); err != nil {
+		t.Fatalf("Load() error: %s", err)
+	}
+
+	// Without an OAuth app for the Enterprise host the authorization fails
+	// before any request is made, which shows that the account was not used
+	// without a token.
+	h, err := c.PromptForHost("ghe.example.com")
+	if err == nil || !strings.Contains(err.Error(), "no OAuth app is configured") {
+		t.Fatalf("PromptForHost() = %+v, %v; want an authorization error", h, err)
+	}
+	if !strings.Contains(errOut.String(), "The token of me on ghe.example.com is missing") {
+		t.Errorf("PromptForHost() reported %q", errOut.String())
+	}
+}
+
+func TestDetectToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		host       string
+		wantToken  string
+		wantSource string
+	}{
+		{"GH_TOKEN for github.com", map[string]string{"GH_TOKEN": "gh", "GITHUB_TOKEN": "github"}, "github.com", "gh", "GH_TOKEN"},
+		{"GITHUB_TOKEN for github.com", map[string]string{"GITHUB_TOKEN": "github"}, "github.com", "github", "GITHUB_TOKEN"},
+		{"enterprise token ignored for github.com", map[string]string{"GH_ENTERPRISE_TOKEN": "ghe"}, "github.com", "", ""},
+		{"GH_ENTERPRISE_TOKEN for other hosts", map[string]string{"GH_ENTERPRISE_TOKEN": "ghe", "GITHUB_ENTERPRISE_TOKEN": "github-ghe"}, "ghe.example.com", "ghe", "GH_ENTERPRISE_TOKEN"},
+		{"GITHUB_ENTERPRISE_TOKEN for other hosts", map[string]string{"GITHUB_ENTERPRISE_TOKEN": "github-ghe"}, "ghe.example.com", "github-ghe", "GITHUB_ENTERPRISE_TOKEN"},
+		{"github.com token ignored for other hosts", map[string]string{"GH_TOKEN": "gh", "GITHUB_TOKEN": "github"}, "ghe.example.com", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{"GH_TOKEN": "", "GITHUB_TOKEN": "", "GH_ENTERPRISE_TOKEN": "", "GITHUB_ENTERPRISE_TOKEN": ""}
+			for name, value := range tt.env {
+				env[name] = value
+			}
+			defer setEnv(env)()
+
+			token, source := (&Config{}).DetectToken(tt.host)
+			if token != tt.wantToken || source != tt.wantSource {
+				t.Errorf("DetectToken(%q) = %q, %q; want %q, %q", tt.host, token, source, tt.wantToken, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestPromptForHostKeepsEnvironmentTokensTemporary(t *testing.T) {
+	defer useConfig(t, "")()
+	defer setEnv(map[string]string{"GH_TOKEN": "env-token", "GITHUB_TOKEN": "", "GITHUB_USER": "me"})()
+
+	c := &Config{}
+	h, err := c.PromptForHost(GitHubHost)
+	if err != nil {
+		t.Fatalf("PromptForHost() error: %s", err)
+	}
+	if h.AccessToken != "env-token" || h.User != "me" {
+		t.Errorf("PromptForHost() = %+v, want the environment token for me", h)
+	}
+	if len(c.Hosts) != 0 {
+		t.Errorf("config.Hosts = %+v, want no hosts added", c.Hosts)
+	}
+	if _, err := os.Stat(configsFile()); !os.IsNotExist(err) {
+		t.Errorf("the configuration was written: %v", err)
+	}
+}
+
+func TestPromptForHostPrefersEnvironmentTokensOverSavedOnes(t *testing.T) {
+	defer useConfig(t, "github.com:\n- user: me\n  oauth_token: saved-token\n  protocol: https\n")()
+	defer setEnv(map[string]string{"GH_TOKEN": "env-token", "GITHUB_TOKEN": "", "GITHUB_USER": "me"})()
+
+	c := &Config{}
+	if err := newConfigService().Load(configsFile(), c); err != nil {
+		t.Fatalf("Load() error: %s", err)
+	}
+	h, err := c.PromptForHost(GitHubHost)
+	if err != nil {
+		t.Fatalf("PromptForHost() error: %s", err)
+	}
+	if h.AccessToken != "env-token" {
+		t.Errorf("AccessToken = %q, want env-token", h.AccessToken)
+	}
+	if len(c.Hosts) != 1 || c.Hosts[0].AccessToken != "saved-token" {
+		t.Errorf("config.Hosts = %+v, want the saved account unchanged", c.Hosts)
+	}
+}
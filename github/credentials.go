@@ -0,0 +1,226 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/npathai/github-cli-clone/git"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// CredentialStore keeps access tokens outside of the configuration file. The
+// configuration only records the Name of the store that holds the token of
+// each host.
+type CredentialStore interface {
+	Name() string
+	Get(host, user string) (string, error)
+	Set(host, user, token string) error
+	Delete(host, user string) error
+}
+
+const (
+	secretServiceStoreName = "secret-service"
+	fileStoreName          = "file"
+	gitStoreName           = "git"
+	plaintextStoreName     = "plaintext"
+)
+
+// defaultCredentialStore picks where new tokens are saved. HUB_CREDENTIAL_STORE
+// selects a store by name, with "plaintext" keeping tokens in the
+// configuration file as before. Otherwise the Secret Service is used when a
+// D-Bus session is available, falling back to the credentials file.
+func defaultCredentialStore() (CredentialStore, error) {
+	if name := os.Getenv("HUB_CREDENTIAL_STORE"); name != "" {
+		if name == plaintextStoreName {
+			return nil, nil
+		}
+		store := credentialStoreNamed(name)
+		if store == nil {
+			return nil, fmt.Errorf("unknown credential store %q in HUB_CREDENTIAL_STORE", name)
+		}
+		return store, nil
+	}
+
+	if secretServiceAvailable() {
+		return &secretServiceStore{}, nil
+	}
+	return newFileCredentialStore(), nil
+}
+
+func credentialStoreNamed(name string) CredentialStore {
+	switch name {
+	case secretServiceStoreName:
+		return &secretServiceStore{}
+	case fileStoreName:
+		return newFileCredentialStore()
+	case gitStoreName:
+		return &gitCredentialStore{}
+	}
+	return nil
+}
+
+// secretServiceStore talks to the freedesktop Secret Service (GNOME Keyring,
+// KWallet) over D-Bus through the secret-tool program of libsecret.
+type secretServiceStore struct{}
+
+func secretServiceAvailable() bool {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return false
+	}
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+func secretServiceAttributes(host, user string) []string {
+	return []string{"service", "hub", "host", host, "user", user}
+}
+
+func (s *secretServiceStore) Name() string {
+	return secretServiceStoreName
+}
+
+func (s *secretServiceStore) Get(host, user string) (string, error) {
+	lookupCmd := exec.Command("secret-tool", append([]string{"lookup"}, secretServiceAttributes(host, user)...)...)
+	output, err := lookupCmd.Output()
+	if err != nil || len(output) == 0 {
+		return "", ErrCredentialNotFound
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (s *secretServiceStore) Set(host, user, token string) error {
+	args := append([]string{"store", "--label", fmt.Sprintf("hub: %s@%s", user, host)}, secretServiceAttributes(host, user)...)
+	storeCmd := exec.Command("secret-tool", args...)
+	storeCmd.Stdin = strings.NewReader(token)
+	if output, err := storeCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error storing token in the Secret Service: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (s *secretServiceStore) Delete(host, user string) error {
+	clearCmd := exec.Command("secret-tool", append([]string{"clear"}, secretServiceAttributes(host, user)...)...)
+	return clearCmd.Run()
+}
+
+// gitCredentialStore shares tokens with the credential helpers configured
+// for git, which then also use them for HTTPS pushes and fetches.
+type gitCredentialStore struct{}
+
+func gitCredentialAttributes(host, user string) map[string]string {
+	return map[string]string{"protocol": "https", "host": host, "username": user}
+}
+
+func (s *gitCredentialStore) Name() string {
+	return gitStoreName
+}
+
+func (s *gitCredentialStore) Get(host, user string) (string, error) {
+	credential, err := git.Credential("fill", gitCredentialAttributes(host, user))
+	if err != nil || credential["password"] == "" {
+		return "", ErrCredentialNotFound
+	}
+	return credential["password"], nil
+}
+
+func (s *gitCredentialStore) Set(host, user, token string) error {
+	attributes := gitCredentialAttributes(host, user)
+	attributes["password"] = token
+	_, err := git.Credential("approve", attributes)
+	return err
+}
+
+func (s *gitCredentialStore) Delete(host, user string) error {
+	_, err := git.Credential("reject", gitCredentialAttributes(host, user))
+	return err
+}
+
+// fileCredentialStore keeps tokens in a JSON file next to the configuration
+// that only the user may read. The tokens are not encrypted: this keeps them
+// out of a configuration file that gets shared or checked in, but offers no
+// protection from anyone with access to the account. The Secret Service is
+// preferred when available.
+type fileCredentialStore struct {
+	Filename string
+}
+
+func newFileCredentialStore() *fileCredentialStore {
+	return &fileCredentialStore{
+		Filename: filepath.Join(filepath.Dir(configsFile()), "hub-credentials"),
+	}
+}
+
+func credentialKey(host, user string) string {
+	return user + "@" + host
+}
+
+func (s *fileCredentialStore) Name() string {
+	return fileStoreName
+}
+
+func (s *fileCredentialStore) Get(host, user string) (string, error) {
+	tokens, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	token, ok := tokens[credentialKey(host, user)]
+	if !ok {
+		return "", ErrCredentialNotFound
+	}
+	return token, nil
+}
+
+func (s *fileCredentialStore) Set(host, user, token string) error {
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	tokens[credentialKey(host, user)] = token
+	return s.write(tokens)
+}
+
+func (s *fileCredentialStore) Delete(host, user string) error {
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	delete(tokens, credentialKey(host, user))
+	return s.write(tokens)
+}
+
+func (s *fileCredentialStore) read() (map[string]string, error) {
+	tokens := map[string]string{}
+	data, err := ioutil.ReadFile(s.Filename)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("%s is corrupt: %s", s.Filename, err)
+	}
+	return tokens, nil
+}
+
+func (s *fileCredentialStore) write(tokens map[string]string) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Filename), 0771); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(s.Filename, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file.
+	return os.Chmod(s.Filename, 0600)
+}
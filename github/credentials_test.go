@@ -0,0 +1,127 @@
+package github
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileCredentialStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gh-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "hub-credentials")
+	store := &fileCredentialStore{Filename: filename}
+
+	if _, err := store.Get("github.com", "me"); err != ErrCredentialNotFound {
+		t.Errorf("Get() before Set() error = %v, want ErrCredentialNotFound", err)
+	}
+
+	if err := store.Set("github.com", "me", "token-1"); err != nil {
+		t.Fatalf("Set() error: %s", err)
+	}
+	if err := store.Set("ghe.example.com", "me", "token-2"); err != nil {
+		t.Fatalf("Set() error: %s", err)
+	}
+	if err := store.Set("github.com", "me", "token-3"); err != nil {
+		t.Fatalf("Set() error: %s", err)
+	}
+
+	// A fresh store reads back what was written.
+	store = &fileCredentialStore{Filename: filename}
+	for host, want := range map[string]string{"github.com": "token-3", "ghe.example.com": "token-2"} {
+		if got, err := store.Get(host, "me"); err != nil || got != want {
+			t.Errorf("Get(%s) = %q, %v; want %q", host, got, err, want)
+		}
+	}
+	if _, err := store.Get("github.com", "you"); err != ErrCredentialNotFound {
+		t.Errorf("Get() of another user error = %v, want ErrCredentialNotFound", err)
+	}
+
+	if err := store.Delete("github.com", "me"); err != nil {
+		t.Fatalf("Delete() error: %s", err)
+	}
+	if _, err := store.Get("github.com", "me"); err != ErrCredentialNotFound {
+		t.Errorf("Get() after Delete() error = %v, want ErrCredentialNotFound", err)
+	}
+	if got, _ := store.Get("ghe.example.com", "me"); got != "token-2" {
+		t.Errorf("Get() of the remaining token = %q, want token-2", got)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("file mode = %o, want 600", mode)
+	}
+}
+
+func TestDefaultCredentialStore(t *testing.T) {
+	defer useConfig(t, "")()
+
+	tests := []struct {
+		env      string
+		dbus     string
+		wantName string
+		wantErr  bool
+	}{
+		{env: "plaintext", wantName: ""},
+		{env: "file", wantName: fileStoreName},
+		{env: "git", wantName: gitStoreName},
+		{env: "secret-service", wantName: secretServiceStoreName},
+		{env: "keychain", wantErr: true},
+		{env: "", dbus: "", wantName: fileStoreName},
+	}
+
+	for _, tt := range tests {
+		restore := setEnv(map[string]string{"HUB_CREDENTIAL_STORE": tt.env, "DBUS_SESSION_BUS_ADDRESS": tt.dbus})
+		store, err := defaultCredentialStore()
+		restore()
+
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("HUB_CREDENTIAL_STORE=%q: want an error", tt.env)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("HUB_CREDENTIAL_STORE=%q: error %s", tt.env, err)
+			continue
+		}
+		name := ""
+		if store != nil {
+			name = store.Name()
+		}
+		if name != tt.wantName {
+			t.Errorf("HUB_CREDENTIAL_STORE=%q: store = %q, want %q", tt.env, name, tt.wantName)
+		}
+	}
+}
+
+func TestConfigServiceKeepsTokensInTheStore(t *testing.T) {
+	defer useConfig(t, "")()
+	defer setEnv(map[string]string{"HUB_CREDENTIAL_STORE": "file"})()
+
+	c := &Config{Hosts: []*Host{{Host: "github.com", User: "me", AccessToken: "secret-token", Protocol: "https"}}}
+	if err := newConfigService().Save(configsFile(), c); err != nil {
+		t.Fatalf("Save() error: %s", err)
+	}
+	data, _ := ioutil.ReadFile(configsFile())
+	if strings.Contains(string(data), "secret-token") || !strings.Contains(string(data), "credential_store: file") {
+		t.Errorf("configuration =\n%s\nwant the token kept in the file store", data)
+	}
+
+	loaded := &Config{}
+	if err := newConfigService().Load(configsFile(), loaded); err != nil {
+		t.Fatalf("Load() error: %s", err)
+	}
+	if len(loaded.Hosts) != 1 || loaded.Hosts[0].AccessToken != "secret-token" {
+		t.Errorf("Load() hosts = %+v, want the token read back", loaded.Hosts)
+	}
+}
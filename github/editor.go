@@ -0,0 +1,180 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/npathai/github-cli-clone/git"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const Scissors = "------------------------ >8 ------------------------"
+
+func NewEditor(filename, topic, message string) (editor *Editor, err error) {
+	gitDir, err := git.Dir()
+	if err != nil {
+		return
+	}
+	messageFile := filepath.Join(gitDir, filename)
+
+	program, err := git.Editor()
+	if err != nil {
+		program = os.Getenv("EDITOR")
+		if program == "" {
+			return nil, err
+		}
+		err = nil
+	}
+
+	cs, err := git.CommentChar(message)
+	if err != nil {
+		return
+	}
+
+	editor = &Editor{
+		Program:    program,
+		Topic:      topic,
+		File:       messageFile,
+		Message:    message,
+		CS:         cs,
+		openEditor: openTextEditor,
+	}
+
+	return
+}
+
+type Editor struct {
+	Program    string
+	Topic      string
+	File       string
+	Message    string
+	CS         string
+	openEditor func(program, file string) error
+}
+
+func (e *Editor) AddCommentedSection(text string) {
+	startRegexp := regexp.MustCompilePOSIX("^")
+	e.Message = e.Message + "\n" + startRegexp.ReplaceAllString(text, e.CS+" ")
+}
+
+func (e *Editor) DeleteFile() error {
+	return os.Remove(e.File)
+}
+
+func (e *Editor) EditContent() (content string, err error) {
+	b, err := e.openAndEdit()
+	if err != nil {
+		return
+	}
+
+	b = bytes.TrimSpace(b)
+	reader := bytes.NewReader(b)
+	scanner := bufio.NewScanner(reader)
+	unquotedLines := []string{}
+
+	scissorsLine := e.CS + " " + Scissors
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == scissorsLine {
+			break
+		}
+		if e.CS == "" || !strings.HasPrefix(line, e.CS) {
+			unquotedLines = append(unquotedLines, line)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+
+	content = strings.Join(unquotedLines, "\n")
+	return
+}
+
+// EditTitleAndBody treats the first paragraph of the edited message as the
+// title and everything after it as the body.
+func (e *Editor) EditTitleAndBody() (title, body string, err error) {
+	content, err := e.EditContent()
+	if err != nil {
+		return
+	}
+
+	title, body = ReadTitleAndBody(strings.NewReader(content))
+	return
+}
+
+func ReadTitleAndBody(r io.Reader) (title, body string) {
+	scanner := bufio.NewScanner(r)
+	titleParts := []string{}
+	bodyParts := []string{}
+	inBody := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !inBody {
+			if strings.TrimSpace(line) == "" {
+				inBody = len(titleParts) > 0
+				continue
+			}
+			titleParts = append(titleParts, strings.TrimSpace(line))
+		} else {
+			bodyParts = append(bodyParts, line)
+		}
+	}
+
+	title = strings.Join(titleParts, " ")
+	body = strings.TrimSpace(strings.Join(bodyParts, "\n"))
+	return
+}
+
+func (e *Editor) openAndEdit() (content []byte, err error) {
+	err = e.writeContent()
+	if err != nil {
+		return
+	}
+
+	err = e.openEditor(e.Program, e.File)
+	if err != nil {
+		err = fmt.Errorf("error using text editor for %s message", e.Topic)
+		defer e.DeleteFile()
+		return
+	}
+
+	content, err = e.readContent()
+
+	return
+}
+
+func (e *Editor) writeContent() (err error) {
+	if !e.isFileExist() {
+		err = ioutil.WriteFile(e.File, []byte(e.Message), 0644)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (e *Editor) isFileExist() bool {
+	_, err := os.Stat(e.File)
+	return err == nil || !os.IsNotExist(err)
+}
+
+func (e *Editor) readContent() (content []byte, err error) {
+	return ioutil.ReadFile(e.File)
+}
+
+func openTextEditor(program, file string) error {
+	editCmd := exec.Command("sh", "-c", program+` "$@"`, program, file)
+	editCmd.Stdin = os.Stdin
+	editCmd.Stdout = os.Stdout
+	editCmd.Stderr = os.Stderr
+
+	return editCmd.Run()
+}
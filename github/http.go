@@ -6,6 +6,7 @@ import (
 	"crypto/md5"
 	"encoding/json"
 	"fmt"
+	"github.com/npathai/github-cli-clone/ui"
 	"github.com/npathai/github-cli-clone/version"
 	"io"
 	"io/ioutil"
@@ -24,6 +25,10 @@ import (
 
 const apiPayloadVersion = "application/vnd.github.v3+json;charset=utf-8"
 const draftsType = "application/vnd.github.shadow-cat-preview+json;charset=utf-8"
+const checksType = "application/vnd.github.antiope-preview+json;charset=utf-8"
+const diffMediaType = "application/vnd.github.v3.diff;charset=utf-8"
+const patchMediaType = "application/vnd.github.v3.patch;charset=utf-8"
+const rawMediaType = "application/vnd.github.v3.raw"
 const cacheVersion = 2
 
 var UserAgent = "Hub " + version.Version
@@ -69,6 +74,107 @@ type verboseTransport struct {
 	Colorized bool
 }
 
+func (t *verboseTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	if t.Verbose {
+		t.dumpRequest(req)
+	}
+
+	if t.OverrideURL != nil {
+		port := "80"
+		if s := strings.Split(req.URL.Host, ":"); len(s) > 1 {
+			port = s[1]
+		}
+
+		req = cloneRequest(req)
+		req.Header.Set("X-Original-Scheme", req.URL.Scheme)
+		req.Header.Set("X-Original-Port", port)
+		req.Host = req.URL.Host
+		req.URL.Scheme = t.OverrideURL.Scheme
+		req.URL.Host = t.OverrideURL.Host
+	}
+
+	resp, err = t.Transport.RoundTrip(req)
+
+	if err == nil && t.Verbose {
+		t.dumpResponse(resp)
+	}
+
+	return
+}
+
+func cloneRequest(req *http.Request) *http.Request {
+	dup := new(http.Request)
+	*dup = *req
+	dup.URL, _ = url.Parse(req.URL.String())
+	dup.Header = make(http.Header)
+	for k, s := range req.Header {
+		dup.Header[k] = s
+	}
+	return dup
+}
+
+func (t *verboseTransport) dumpRequest(req *http.Request) {
+	info := fmt.Sprintf("> %s %s://%s%s", req.Method, req.URL.Scheme, req.URL.Host, req.URL.RequestURI())
+	t.verbosePrintln(info)
+	t.dumpHeaders(req.Header, ">")
+	body := t.dumpBody(req.Body)
+	if body != nil {
+		// reset body since it's been read
+		req.Body = body
+	}
+}
+
+func (t *verboseTransport) dumpResponse(resp *http.Response) {
+	info := fmt.Sprintf("< HTTP %d", resp.StatusCode)
+	t.verbosePrintln(info)
+	t.dumpHeaders(resp.Header, "<")
+	body := t.dumpBody(resp.Body)
+	if body != nil {
+		// reset body since it's been read
+		resp.Body = body
+	}
+}
+
+func (t *verboseTransport) dumpHeaders(header http.Header, indent string) {
+	for _, listed := range []string{"Authorization", "Content-Type", "Link", "Location", "X-GitHub-OTP"} {
+		v := header.Get(listed)
+		if v == "" {
+			continue
+		}
+		if listed == "Authorization" {
+			v = regexp.MustCompile(`\w+$`).ReplaceAllString(v, "[REDACTED]")
+		}
+		t.verbosePrintln(fmt.Sprintf("%s %s: %s", indent, listed, v))
+	}
+}
+
+func (t *verboseTransport) dumpBody(body io.ReadCloser) io.ReadCloser {
+	if body == nil {
+		return nil
+	}
+
+	defer body.Close()
+	buf := new(bytes.Buffer)
+	_, err := io.Copy(buf, body)
+	if err != nil {
+		return nil
+	}
+
+	if buf.Len() > 0 {
+		t.verbosePrintln(buf.String())
+	}
+
+	return ioutil.NopCloser(buf)
+}
+
+func (t *verboseTransport) verbosePrintln(msg string) {
+	if t.Colorized {
+		msg = fmt.Sprintf("\033[36m%s\033[0m", msg)
+	}
+
+	fmt.Fprintln(t.Out, msg)
+}
+
 // An implementation of http.ProxyFromEnvironment that isn't broken
 func proxyFromEnvironment(req *http.Request) (*url.URL, error) {
 	proxy := os.Getenv("http_proxy")
@@ -127,9 +233,8 @@ func newHttpClient(testHost string, verbose bool, unixSocket string) *http.Clien
 		Transport:   httpTransport,
 		Verbose:     verbose,
 		OverrideURL: testURL,
-		// FIXME add these once we incorporate ui package
-		Out:         nil,
-		Colorized:   nil,
+		Out:         ui.Stderr,
+		Colorized:   ui.IsTerminal(os.Stderr),
 	}
 
 	return &http.Client{
@@ -368,6 +473,18 @@ func (client *simpleClient) PostJSON(path string, payload interface{}) (*simpleR
 	return client.jsonRequest("POST", path, payload, nil)
 }
 
+func (client *simpleClient) PatchJSON(path string, payload interface{}) (*simpleResponse, error) {
+	return client.jsonRequest("PATCH", path, payload, nil)
+}
+
+func (client *simpleClient) PutJSON(path string, payload interface{}) (*simpleResponse, error) {
+	return client.jsonRequest("PUT", path, payload, nil)
+}
+
+func (c *simpleClient) Delete(path string) (*simpleResponse, error) {
+	return c.PerformRequest("DELETE", path, nil, nil)
+}
+
 func (c *simpleClient) Get(path string) (*simpleResponse, error) {
-	return c.performRequest("GET", path, nil, nil)
+	return c.PerformRequest("GET", path, nil, nil)
 }
\ No newline at end of file
@@ -0,0 +1,180 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// OAuthClientIDEnv names the environment variable holding the client ID of
+// the OAuth app that tokens are requested for, unless the host entry
+// configures one with oauth_client_id.
+const OAuthClientIDEnv = "HUB_OAUTH_CLIENT_ID"
+
+// DefaultOAuthClientID is the OAuth app used for github.com when neither the
+// host entry nor the environment names one. Builds registering their own app
+// set it with -ldflags "-X github.com/npathai/github-cli-clone/github.DefaultOAuthClientID=<id>".
+// Enterprise hosts have no default, since apps are registered per instance.
+var DefaultOAuthClientID = "178c6fc778ccc68e1d6a"
+
+var oauthScopes = []string{"repo", "read:org", "gist"}
+
+// DeviceCode is the response to a device authorization request. The user
+// enters UserCode at VerificationURI while the device code is used to poll
+// for the token.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+type deviceTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	Interval         int    `json:"interval"`
+}
+
+// DeviceFlow implements the OAuth device authorization grant against the
+// web host of a GitHub instance.
+type DeviceFlow struct {
+	BaseURL    *url.URL
+	ClientID   string
+	Scopes     []string
+	HTTPClient *http.Client
+
+	// Sleep waits between polls; it defaults to time.Sleep.
+	Sleep func(time.Duration)
+}
+
+func newDeviceFlow(host *Host) (*DeviceFlow, error) {
+	protocol := host.webProtocol()
+	if protocol == "" {
+		protocol = "https"
+	}
+	clientID := host.OAuthClientID
+	if clientID == "" {
+		clientID = os.Getenv(OAuthClientIDEnv)
+	}
+	if clientID == "" && host.Host == GitHubHost {
+		clientID = DefaultOAuthClientID
+	}
+	if clientID == "" {
+		return nil, fmt.Errorf("no OAuth app is configured for %s; set oauth_client_id for the host in %s or the %s environment variable, or log in with --with-token", host.Host, configsFile(), OAuthClientIDEnv)
+	}
+
+	return &DeviceFlow{
+		BaseURL:    &url.URL{Scheme: protocol, Host: host.Host, Path: "/"},
+		ClientID:   clientID,
+		Scopes:     oauthScopes,
+		HTTPClient: newHttpClient(os.Getenv("HUB_TEST_HOST"), os.Getenv("HUB_VERBOSE") != "", os.ExpandEnv(host.UnixSocket)),
+	}, nil
+}
+
+// RequestCode starts the flow by asking for a device and a user code.
+func (f *DeviceFlow) RequestCode() (*DeviceCode, error) {
+	params := url.Values{}
+	params.Set("client_id", f.ClientID)
+	params.Set("scope", strings.Join(f.Scopes, " "))
+
+	code := &DeviceCode{}
+	if err := f.post("login/device/code", params, code); err != nil {
+		return nil, fmt.Errorf("error requesting device code: %s", err)
+	}
+	if code.DeviceCode == "" || code.UserCode == "" {
+		return nil, fmt.Errorf("error requesting device code: the response is missing the codes; is device flow enabled for OAuth app %s?", f.ClientID)
+	}
+	return code, nil
+}
+
+// PollToken waits for the user to authorize the device code and returns the
+// access token. It polls no faster than the interval given by the server
+// and backs off further whenever it is told to slow down.
+func (f *DeviceFlow) PollToken(code *DeviceCode) (string, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	expiresIn := time.Duration(code.ExpiresIn) * time.Second
+	sleep := f.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	params := url.Values{}
+	params.Set("client_id", f.ClientID)
+	params.Set("device_code", code.DeviceCode)
+	params.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+
+	var waited time.Duration
+	for {
+		sleep(interval)
+		waited += interval
+		if expiresIn > 0 && waited > expiresIn {
+			return "", fmt.Errorf("the device code has expired; please try again")
+		}
+
+		res := &deviceTokenResponse{}
+		if err := f.post("login/oauth/access_token", params, res); err != nil {
+			return "", fmt.Errorf("error requesting access token: %s", err)
+		}
+
+		switch res.Error {
+		case "":
+			if res.AccessToken == "" {
+				return "", fmt.Errorf("error requesting access token: the response has no token")
+			}
+			return res.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			if res.Interval > 0 {
+				interval = time.Duration(res.Interval) * time.Second
+			} else {
+				interval += 5 * time.Second
+			}
+		case "expired_token":
+			return "", fmt.Errorf("the device code has expired; please try again")
+		case "access_denied":
+			return "", fmt.Errorf("the authorization request was denied")
+		default:
+			message := res.ErrorDescription
+			if message == "" {
+				message = res.Error
+			}
+			return "", fmt.Errorf("error requesting access token: %s", message)
+		}
+	}
+}
+
+func (f *DeviceFlow) post(path string, params url.Values, dest interface{}) error {
+	u := f.BaseURL.ResolveReference(&url.URL{Path: path})
+	req, err := http.NewRequest("POST", u.String(), strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+
+	res, err := f.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != 200 {
+		return fmt.Errorf("%s (HTTP %d)", http.StatusText(res.StatusCode), res.StatusCode)
+	}
+	return json.Unmarshal(body, dest)
+}
@@ -0,0 +1,181 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubDeviceFlow returns a device flow against a server answering each token
+// poll with the next of responses, and the durations it sleeps between polls.
+func stubDeviceFlow(t *testing.T, responses []string) (*DeviceFlow, *[]time.Duration, func()) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.Form.Get("client_id") != "test-client" {
+			t.Errorf("client_id = %q, want test-client", r.Form.Get("client_id"))
+		}
+
+		switch r.URL.Path {
+		case "/login/device/code":
+			if scope := r.Form.Get("scope"); scope != "repo gist" {
+				t.Errorf("scope = %q, want %q", scope, "repo gist")
+			}
+			fmt.Fprint(w, `{"device_code": "device", "user_code": "ABCD-1234", "verification_uri": "https://github.com/login/device", "expires_in": 900, "interval": 5}`)
+		case "/login/oauth/access_token":
+			if r.Form.Get("device_code") != "device" || r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:device_code" {
+				t.Errorf("unexpected token request %s", r.Form.Encode())
+			}
+			if polls >= len(responses) {
+				t.Fatalf("unexpected poll %d", polls+1)
+			}
+			fmt.Fprint(w, responses[polls])
+			polls++
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	sleeps := []time.Duration{}
+	baseURL, _ := url.Parse(server.URL + "/")
+	flow := &DeviceFlow{
+		BaseURL:    baseURL,
+		ClientID:   "test-client",
+		Scopes:     []string{"repo", "gist"},
+		HTTPClient: server.Client(),
+		Sleep:      func(d time.Duration) { sleeps = append(sleeps, d) },
+	}
+	return flow, &sleeps, server.Close
+}
+
+func TestDeviceFlow(t *testing.T) {
+	tests := []struct {
+		name       string
+		responses  []string
+		wantToken  string
+		wantErr    string
+		wantSleeps []time.Duration
+	}{
+		{
+			name: "success after pending and slow_down",
+			responses: []string{
+				`{"error": "authorization_pending"}`,
+				`{"error": "slow_down", "interval": 10}`,
+				`{"error": "slow_down"}`,
+				`{"error": "authorization_pending"}`,
+				`{"access_token": "gho_token", "token_type": "bearer"}`,
+			},
+			wantToken:  "gho_token",
+			wantSleeps: []time.Duration{5 * time.Second, 5 * time.Second, 10 * time.Second, 15 * time.Second, 15 * time.Second},
+		},
+		{
+			name:       "expired token",
+			responses:  []string{`{"error": "authorization_pending"}`, `{"error": "expired_token"}`},
+			wantErr:    "the device code has expired",
+			wantSleeps: []time.Duration{5 * time.Second, 5 * time.Second},
+		},
+		{
+			name:       "access denied",
+			responses:  []string{`{"error": "access_denied"}`},
+			wantErr:    "the authorization request was denied",
+			wantSleeps: []time.Duration{5 * time.Second},
+		},
+		{
+			name:       "other errors",
+			responses:  []string{`{"error": "incorrect_client_credentials", "error_description": "The client_id is not valid."}`},
+			wantErr:    "The client_id is not valid.",
+			wantSleeps: []time.Duration{5 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow, sleeps, done := stubDeviceFlow(t, tt.responses)
+			defer done()
+
+			code, err := flow.RequestCode()
+			if err != nil {
+				t.Fatalf("RequestCode() error: %s", err)
+			}
+			if code.UserCode != "ABCD-1234" || code.VerificationURI != "https://github.com/login/device" {
+				t.Errorf("RequestCode() = %+v", code)
+			}
+
+			token, err := flow.PollToken(code)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("PollToken() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("PollToken() error: %s", err)
+			} else if token != tt.wantToken {
+				t.Errorf("PollToken() = %q, want %q", token, tt.wantToken)
+			}
+			if fmt.Sprint(*sleeps) != fmt.Sprint(tt.wantSleeps) {
+				t.Errorf("slept %v, want %v", *sleeps, tt.wantSleeps)
+			}
+		})
+	}
+}
+
+func TestDeviceFlowGivesUpWhenTheCodeExpires(t *testing.T) {
+	flow, sleeps, done := stubDeviceFlow(t, []string{`{"error": "authorization_pending"}`, `{"error": "authorization_pending"}`})
+	defer done()
+
+	_, err := flow.PollToken(&DeviceCode{DeviceCode: "device", ExpiresIn: 12, Interval: 5})
+	if err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("PollToken() error = %v, want an expiry error", err)
+	}
+	if len(*sleeps) != 3 {
+		t.Errorf("slept %v, want 3 waits", *sleeps)
+	}
+}
+
+func TestNewDeviceFlowClientID(t *testing.T) {
+	defer useConfig(t, "")()
+	defer setEnv(map[string]string{OAuthClientIDEnv: ""})()
+
+	tests := []struct {
+		name string
+		env  string
+		host *Host
+		want string
+	}{
+		{"default app for github.com", "", &Host{Host: "github.com"}, DefaultOAuthClientID},
+		{"environment overrides the default", "env-client", &Host{Host: "github.com"}, "env-client"},
+		{"host entry overrides the environment", "env-client", &Host{Host: "github.com", OAuthClientID: "host-client"}, "host-client"},
+		{"host entry for enterprise", "", &Host{Host: "ghe.example.com", OAuthClientID: "host-client"}, "host-client"},
+		{"environment for enterprise", "env-client", &Host{Host: "ghe.example.com"}, "env-client"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setEnv(map[string]string{OAuthClientIDEnv: tt.env})()
+			flow, err := newDeviceFlow(tt.host)
+			if err != nil {
+				t.Fatalf("newDeviceFlow() error: %s", err)
+			}
+			if flow.ClientID != tt.want {
+				t.Errorf("ClientID = %q, want %q", flow.ClientID, tt.want)
+			}
+		})
+	}
+
+	flow, err := newDeviceFlow(&Host{Host: "ghe.example.com", Protocol: "http", OAuthClientID: "host-client"})
+	if err != nil {
+		t.Fatalf("newDeviceFlow() error: %s", err)
+	}
+	if flow.BaseURL.String() != "http://ghe.example.com/" {
+		t.Errorf("BaseURL = %s, want http://ghe.example.com/", flow.BaseURL)
+	}
+
+	if _, err := newDeviceFlow(&Host{Host: "ghe.example.com"}); err == nil || !strings.Contains(err.Error(), OAuthClientIDEnv) {
+		t.Errorf("newDeviceFlow() for an enterprise host without an app error = %v, want a missing client ID error", err)
+	}
+}
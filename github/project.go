@@ -1,8 +1,170 @@
 package github
 
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
 type Project struct {
 	Name     string
 	Owner    string
 	Host     string
 	Protocol string
 }
+
+func (p Project) String() string {
+	return fmt.Sprintf("%s/%s", p.Owner, p.Name)
+}
+
+func (p *Project) WebURL(name, owner, path string) string {
+	if owner == "" {
+		owner = p.Owner
+	}
+	if name == "" {
+		name = p.Name
+	}
+
+	ownerWithName := fmt.Sprintf("%s/%s", owner, name)
+	url := fmt.Sprintf("%s://%s/%s", p.Protocol, p.Host, ownerWithName)
+	if path != "" {
+		url = fmt.Sprintf("%s/%s", url, path)
+	}
+
+	return url
+}
+
+func (p *Project) GitURL(name, owner string, isSSH bool) string {
+	if owner == "" {
+		owner = p.Owner
+	}
+	if name == "" {
+		name = p.Name
+	}
+
+	if isSSH {
+		return fmt.Sprintf("git@%s:%s/%s.git", p.Host, owner, name)
+	}
+	return fmt.Sprintf("%s://%s/%s/%s.git", p.Protocol, p.Host, owner, name)
+}
+
+func (p *Project) SameAs(other *Project) bool {
+	return strings.EqualFold(p.Owner, other.Owner) &&
+		strings.EqualFold(p.Name, other.Name) &&
+		strings.EqualFold(p.Host, other.Host)
+}
+
+type GithubHostError struct {
+	url *url.URL
+}
+
+func (e *GithubHostError) Error() string {
+	return fmt.Sprintf("Invalid GitHub URL: %s", e.url)
+}
+
+func NewProjectFromURL(url *url.URL) (p *Project, err error) {
+	if url == nil {
+		err = fmt.Errorf("missing GitHub URL")
+		return
+	}
+
+	if !knownGitHubHostsInclude(url.Host) {
+		err = &GithubHostError{url}
+		return
+	}
+
+	parts := strings.SplitN(url.Path, "/", 4)
+	if len(parts) <= 2 {
+		err = fmt.Errorf("Invalid GitHub URL: %s", url)
+		return
+	}
+
+	name := strings.TrimSuffix(parts[2], ".git")
+	p = newProject(parts[1], name, url.Host, url.Scheme)
+
+	return
+}
+
+func NewProject(owner, name, host string) *Project {
+	return newProject(owner, name, host, "")
+}
+
+func newProject(owner, name, host, protocol string) *Project {
+	if strings.Contains(owner, "/") {
+		result := strings.SplitN(owner, "/", 2)
+		owner = result[0]
+		if name == "" {
+			name = result[1]
+		}
+	} else if strings.Contains(name, "/") {
+		result := strings.SplitN(name, "/", 2)
+		owner = result[0]
+		name = result[1]
+	}
+
+	if host == "" {
+		host = DefaultGitHubHost()
+	}
+	if host == "ssh.github.com" {
+		host = GitHubHost
+	}
+
+	if protocol != "http" && protocol != "https" {
+		protocol = ""
+	}
+	if protocol == "" {
+		protocol = CurrentConfig().Find(host).webProtocol()
+	}
+	if protocol == "" {
+		protocol = "https"
+	}
+
+	if owner == "" {
+		if h := CurrentConfig().Find(host); h != nil {
+			owner = h.User
+		}
+	}
+
+	return &Project{
+		Name:     name,
+		Owner:    owner,
+		Host:     strings.ToLower(host),
+		Protocol: protocol,
+	}
+}
+
+// DefaultGitHubHost returns the host set with GH_HOST, or its older name
+// GITHUB_HOST, falling back to github.com.
+func DefaultGitHubHost() string {
+	for _, name := range []string{"GH_HOST", "GITHUB_HOST"} {
+		if defaultHost := os.Getenv(name); defaultHost != "" {
+			return strings.ToLower(defaultHost)
+		}
+	}
+
+	return GitHubHost
+}
+
+func knownGitHubHosts() []string {
+	hosts := []string{GitHubHost, "ssh.github.com", "github.localhost"}
+	if defaultHost := DefaultGitHubHost(); defaultHost != GitHubHost {
+		hosts = append(hosts, defaultHost)
+	}
+
+	for _, h := range CurrentConfig().Hosts {
+		hosts = append(hosts, h.Host)
+	}
+
+	return hosts
+}
+
+func knownGitHubHostsInclude(host string) bool {
+	for _, h := range knownGitHubHosts() {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -86,6 +86,14 @@ func newRemote(name string, urlTypeMap map[string]string) (Remote, error) {
 	return remote, nil
 }
 
+func (remote *Remote) String() string {
+	return remote.Name
+}
+
 func (remote *Remote) Project() (*Project, error) {
-	return &Project{}, nil
+	p, err := NewProjectFromURL(remote.URL)
+	if _, ok := err.(*GithubHostError); ok || remote.URL == nil {
+		return NewProjectFromURL(remote.PushURL)
+	}
+	return p, err
 }
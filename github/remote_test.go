@@ -0,0 +1,134 @@
+package github
+
+import (
+	"github.com/npathai/github-cli-clone/git"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useConfig points the configuration at a temporary file holding content and
+// returns a function restoring the previous environment.
+func useConfig(t *testing.T, content string) func() {
+	dir, err := ioutil.TempDir("", "gh-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "hub")
+	if content != "" {
+		if err := ioutil.WriteFile(filename, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	restore := setEnv(map[string]string{
+		"HUB_CONFIG":           filename,
+		"HUB_CREDENTIAL_STORE": "plaintext",
+	})
+	return func() {
+		restore()
+		os.RemoveAll(dir)
+	}
+}
+
+// setEnv sets the variables, unsetting those given as empty strings, and
+// returns a function restoring their previous values.
+func setEnv(vars map[string]string) func() {
+	previous := map[string]*string{}
+	for name, value := range vars {
+		if old, ok := os.LookupEnv(name); ok {
+			previous[name] = &old
+		} else {
+			previous[name] = nil
+		}
+		if value == "" {
+			os.Unsetenv(name)
+		} else {
+			os.Setenv(name, value)
+		}
+	}
+	return func() {
+		for name, old := range previous {
+			if old == nil {
+				os.Unsetenv(name)
+			} else {
+				os.Setenv(name, *old)
+			}
+		}
+	}
+}
+
+func parseRemoteURL(t *testing.T, rawURL string) *url.URL {
+	if rawURL == "" {
+		return nil
+	}
+	parser := &git.URLParser{SSHConfig: git.SSHConfig{}}
+	u, err := parser.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("parsing %s: %s", rawURL, err)
+	}
+	return u
+}
+
+func TestRemoteProject(t *testing.T) {
+	defer useConfig(t, "")()
+	defer setEnv(map[string]string{"GH_HOST": "", "GITHUB_HOST": ""})()
+
+	tests := []struct {
+		name     string
+		fetchURL string
+		pushURL  string
+		want     string
+		host     string
+		protocol string
+	}{
+		{"https", "https://github.com/octo/hello.git", "", "octo/hello", "github.com", "https"},
+		{"scp-like ssh", "git@github.com:octo/hello.git", "", "octo/hello", "github.com", "https"},
+		{"ssh scheme", "ssh://git@github.com/octo/hello", "", "octo/hello", "github.com", "https"},
+		{"ssh.github.com", "ssh://git@ssh.github.com:443/octo/hello.git", "", "octo/hello", "github.com", "https"},
+		{"mixed case host", "https://GitHub.com/octo/hello", "", "octo/hello", "github.com", "https"},
+		{"push URL fallback", "https://example.com/octo/mirror.git", "git@github.com:octo/hello.git", "octo/hello", "github.com", "https"},
+		{"push URL only", "", "https://github.com/octo/hello.git", "octo/hello", "github.com", "https"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := &Remote{Name: "origin", URL: parseRemoteURL(t, tt.fetchURL), PushURL: parseRemoteURL(t, tt.pushURL)}
+			project, err := remote.Project()
+			if err != nil {
+				t.Fatalf("Project() error: %s", err)
+			}
+			if project.String() != tt.want || project.Host != tt.host || project.Protocol != tt.protocol {
+				t.Errorf("Project() = %s on %s://%s, want %s on %s://%s", project, project.Protocol, project.Host, tt.want, tt.protocol, tt.host)
+			}
+		})
+	}
+}
+
+func TestRemoteProjectRejectsOtherHosts(t *testing.T) {
+	defer useConfig(t, "")()
+	defer setEnv(map[string]string{"GH_HOST": "", "GITHUB_HOST": ""})()
+
+	for _, rawURL := range []string{"https://example.com/octo/hello.git", "https://github.com/octo"} {
+		remote := &Remote{Name: "origin", URL: parseRemoteURL(t, rawURL)}
+		if project, err := remote.Project(); err == nil {
+			t.Errorf("Project() for %s = %s, want an error", rawURL, project)
+		}
+	}
+}
+
+func TestRemoteProjectUsesConfiguredHosts(t *testing.T) {
+	defer useConfig(t, "git.corp.example:\n- user: me\n  protocol: http\n")()
+	defer setEnv(map[string]string{"GH_HOST": "", "GITHUB_HOST": ""})()
+
+	remote := &Remote{Name: "origin", URL: parseRemoteURL(t, "git@git.corp.example:team/service.git")}
+	project, err := remote.Project()
+	if err != nil {
+		t.Fatalf("Project() error: %s", err)
+	}
+	if project.String() != "team/service" || project.Host != "git.corp.example" || project.Protocol != "http" {
+		t.Errorf("Project() = %s on %s://%s", project, project.Protocol, project.Host)
+	}
+}
@@ -0,0 +1,164 @@
+package github
+
+import (
+	"bytes"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	issueTemplateName       = "ISSUE_TEMPLATE"
+	pullRequestTemplateName = "PULL_REQUEST_TEMPLATE"
+)
+
+// Template is an issue or pull request template found in the repository.
+// Name, About, Title, Labels and Assignees come from the optional YAML front
+// matter; Body is the rest of the file.
+type Template struct {
+	Path      string
+	Name      string
+	About     string
+	Title     string
+	Labels    []string
+	Assignees []string
+	Body      string
+}
+
+type templateFrontMatter struct {
+	Name      string      `yaml:"name"`
+	About     string      `yaml:"about"`
+	Title     string      `yaml:"title"`
+	Labels    interface{} `yaml:"labels"`
+	Assignees interface{} `yaml:"assignees"`
+}
+
+// IssueTemplates returns the issue templates of the given working tree.
+func IssueTemplates(workdir string) []*Template {
+	return findTemplates(workdir, issueTemplateName)
+}
+
+// PullRequestTemplates returns the pull request templates of the given
+// working tree.
+func PullRequestTemplates(workdir string) []*Template {
+	return findTemplates(workdir, pullRequestTemplateName)
+}
+
+// findTemplates looks for `<name>.md` and `<name>/*.md` in the root of the
+// working tree, in .github and in docs, the places GitHub itself looks.
+func findTemplates(workdir, name string) []*Template {
+	templates := []*Template{}
+	for _, dir := range []string{workdir, filepath.Join(workdir, ".github"), filepath.Join(workdir, "docs")} {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, f := range files {
+			var paths []string
+			if f.IsDir() && strings.EqualFold(f.Name(), name) {
+				paths = templateFiles(filepath.Join(dir, f.Name()))
+			} else if !f.IsDir() && strings.EqualFold(f.Name(), name+".md") {
+				paths = []string{filepath.Join(dir, f.Name())}
+			}
+
+			for _, path := range paths {
+				if t, err := ReadTemplate(path); err == nil {
+					templates = append(templates, t)
+				}
+			}
+		}
+	}
+	return templates
+}
+
+func templateFiles(dir string) []string {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	paths := []string{}
+	for _, f := range files {
+		if !f.IsDir() && strings.EqualFold(filepath.Ext(f.Name()), ".md") {
+			paths = append(paths, filepath.Join(dir, f.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ReadTemplate reads a template file, splitting off its front matter.
+func ReadTemplate(path string) (*Template, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Template{Path: path}
+	frontMatter, body := splitFrontMatter(content)
+	if frontMatter != nil {
+		var fm templateFrontMatter
+		if err := yaml.Unmarshal(frontMatter, &fm); err != nil {
+			return nil, err
+		}
+		t.Name = fm.Name
+		t.About = fm.About
+		t.Title = fm.Title
+		t.Labels = frontMatterList(fm.Labels)
+		t.Assignees = frontMatterList(fm.Assignees)
+	}
+	t.Body = string(body)
+
+	if t.Name == "" {
+		base := filepath.Base(path)
+		t.Name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return t, nil
+}
+
+// splitFrontMatter separates a leading block delimited by `---` lines from
+// the rest of the content. The front matter is nil when there is none, or
+// when the closing `---` line is missing.
+func splitFrontMatter(content []byte) (frontMatter, body []byte) {
+	content = bytes.Replace(content, []byte("\r\n"), []byte("\n"), -1)
+	if !bytes.HasPrefix(content, []byte("---\n")) {
+		return nil, content
+	}
+
+	rest := content[len("---\n"):]
+	for offset := 0; offset < len(rest); {
+		end, next := len(rest), len(rest)
+		if i := bytes.IndexByte(rest[offset:], '\n'); i >= 0 {
+			end, next = offset+i, offset+i+1
+		}
+		if bytes.Equal(bytes.TrimRight(rest[offset:end], " \t"), []byte("---")) {
+			return rest[:offset], rest[next:]
+		}
+		offset = next
+	}
+	return nil, content
+}
+
+// frontMatterList accepts both a YAML list and a comma separated string,
+// as GitHub does for labels and assignees.
+func frontMatterList(value interface{}) []string {
+	list := []string{}
+	switch v := value.(type) {
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				list = append(list, strings.TrimSpace(s))
+			}
+		}
+	}
+	return list
+}
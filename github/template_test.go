@@ -0,0 +1,151 @@
+package github
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		frontMatter string
+		hasFront    bool
+		body        string
+	}{
+		{
+			name:    "no front matter",
+			content: "## Summary\n\n---\nname: not front matter\n---\n",
+			body:    "## Summary\n\n---\nname: not front matter\n---\n",
+		},
+		{
+			name:        "front matter",
+			content:     "---\nname: Bug\n---\n## Steps\n",
+			frontMatter: "name: Bug\n",
+			hasFront:    true,
+			body:        "## Steps\n",
+		},
+		{
+			name:        "empty front matter",
+			content:     "---\n---\nbody",
+			frontMatter: "",
+			hasFront:    true,
+			body:        "body",
+		},
+		{
+			name:        "nothing after the front matter",
+			content:     "---\nname: Bug\n---",
+			frontMatter: "name: Bug\n",
+			hasFront:    true,
+			body:        "",
+		},
+		{
+			name:    "unterminated",
+			content: "---\nname: Bug\n## Steps\n",
+			body:    "---\nname: Bug\n## Steps\n",
+		},
+		{
+			name:    "delimiter only as a prefix",
+			content: "---\nname: Bug\n----\n--- body\n",
+			body:    "---\nname: Bug\n----\n--- body\n",
+		},
+		{
+			name:        "CRLF line endings",
+			content:     "---\r\nname: Bug\r\n---\r\n## Steps\r\n",
+			frontMatter: "name: Bug\n",
+			hasFront:    true,
+			body:        "## Steps\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frontMatter, body := splitFrontMatter([]byte(tt.content))
+			if (frontMatter != nil) != tt.hasFront || string(frontMatter) != tt.frontMatter || string(body) != tt.body {
+				t.Errorf("splitFrontMatter() = %q (nil: %v), %q, want %q (nil: %v), %q",
+					frontMatter, frontMatter == nil, body, tt.frontMatter, !tt.hasFront, tt.body)
+			}
+		})
+	}
+}
+
+func TestFrontMatterList(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  []string
+	}{
+		{nil, []string{}},
+		{"bug", []string{"bug"}},
+		{"bug, help wanted ,,", []string{"bug", "help wanted"}},
+		{[]interface{}{"bug", " help wanted ", "", 3}, []string{"bug", "help wanted"}},
+		{42, []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := frontMatterList(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("frontMatterList(%#v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestReadTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gh-template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		file    string
+		content string
+		want    Template
+	}{
+		{
+			file:    "bug_report.md",
+			content: "---\r\nname: Bug report\r\nabout: Something is broken\r\ntitle: \"[bug] \"\r\nlabels: bug, triage\r\nassignees:\r\n  - mona\r\n  - hubot\r\n---\r\n## Steps\r\n",
+			want: Template{
+				Name:      "Bug report",
+				About:     "Something is broken",
+				Title:     "[bug] ",
+				Labels:    []string{"bug", "triage"},
+				Assignees: []string{"mona", "hubot"},
+				Body:      "## Steps\n",
+			},
+		},
+		{
+			file:    "feature.md",
+			content: "---\nlabels: [enhancement]\nassignees: mona\n---\nDescribe it\n",
+			want: Template{
+				Name:      "feature",
+				Labels:    []string{"enhancement"},
+				Assignees: []string{"mona"},
+				Body:      "Describe it\n",
+			},
+		},
+		{
+			file:    "plain.md",
+			content: "Describe it\n",
+			want:    Template{Name: "plain", Body: "Describe it\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.file)
+		if err := ioutil.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ReadTemplate(path)
+		if err != nil {
+			t.Errorf("ReadTemplate(%s) error: %s", tt.file, err)
+			continue
+		}
+		tt.want.Path = path
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("ReadTemplate(%s) = %+v, want %+v", tt.file, *got, tt.want)
+		}
+	}
+}
@@ -3,8 +3,11 @@ module github.com/npathai/github-cli-clone
 go 1.13
 
 require (
-  github.com/spf13/cobra v0.0.5
-  github.com/mattn/go-colorable v0.1.2
-  github.com/mattn/go-isatty v0.0.9
-  golang.org/x/crypto v0.0.0-20190926180335-cea2066c6411
-)
\ No newline at end of file
+	github.com/BurntSushi/toml v0.3.1
+	github.com/mattn/go-colorable v0.1.2
+	github.com/mattn/go-isatty v0.0.9
+	github.com/mitchellh/go-homedir v1.1.0
+	github.com/spf13/cobra v0.0.5
+	golang.org/x/crypto v0.0.0-20190926180335-cea2066c6411
+	gopkg.in/yaml.v2 v2.2.2
+)
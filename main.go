@@ -9,6 +9,9 @@ import (
 
 func main() {
   if err := command.RootCmd.Execute(); err != nil {
+    if exitErr, ok := err.(*command.ExitError); ok {
+      os.Exit(exitErr.Code)
+    }
     fmt.Println(err)
     os.Exit(1)
   }
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	colorReset   = 0
+	colorRed     = 31
+	colorGreen   = 32
+	colorYellow  = 33
+	colorBlue    = 34
+	colorMagenta = 35
+	colorCyan    = 36
+	colorGray    = 90
+)
+
+var colorEnabled = IsTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+
+func IsColorEnabled() bool {
+	return colorEnabled
+}
+
+func SetColorEnabled(enabled bool) {
+	colorEnabled = enabled
+}
+
+func colorize(code int, text string) string {
+	if !colorEnabled {
+		return text
+	}
+	return fmt.Sprintf("\033[%dm%s\033[%dm", code, text, colorReset)
+}
+
+func Bold(text string) string {
+	if !colorEnabled {
+		return text
+	}
+	return fmt.Sprintf("\033[1m%s\033[%dm", text, colorReset)
+}
+
+func Red(text string) string {
+	return colorize(colorRed, text)
+}
+
+func Green(text string) string {
+	return colorize(colorGreen, text)
+}
+
+func Yellow(text string) string {
+	return colorize(colorYellow, text)
+}
+
+func Blue(text string) string {
+	return colorize(colorBlue, text)
+}
+
+func Magenta(text string) string {
+	return colorize(colorMagenta, text)
+}
+
+func Cyan(text string) string {
+	return colorize(colorCyan, text)
+}
+
+func Gray(text string) string {
+	return colorize(colorGray, text)
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// StartPager pipes output through $PAGER when stdout is a terminal. $PAGER
+// is split into words the way a shell would, without involving one. The
+// returned function must be called once all output has been written.
+func StartPager() (io.Writer, func() error, error) {
+	noop := func() error { return nil }
+	if !IsTerminal(os.Stdout) {
+		return Stdout, noop, nil
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		if _, err := exec.LookPath("less"); err != nil {
+			return Stdout, noop, nil
+		}
+		pager = "less"
+	}
+	args, err := splitShellWords(pager)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid $PAGER: %s", err)
+	}
+	if len(args) == 0 || args[0] == "cat" && len(args) == 1 {
+		return Stdout, noop, nil
+	}
+
+	pagerCmd := exec.Command(args[0], args[1:]...)
+	pagerCmd.Env = os.Environ()
+	if os.Getenv("LESS") == "" {
+		pagerCmd.Env = append(pagerCmd.Env, "LESS=FRX")
+	}
+	if os.Getenv("LV") == "" {
+		pagerCmd.Env = append(pagerCmd.Env, "LV=-c")
+	}
+	pagerCmd.Stdout = os.Stdout
+	pagerCmd.Stderr = os.Stderr
+
+	in, err := pagerCmd.StdinPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := pagerCmd.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	return in, func() error {
+		in.Close()
+		return pagerCmd.Wait()
+	}, nil
+}
+
+// splitShellWords splits s into words separated by blanks, honoring single
+// and double quotes and backslash escapes as a POSIX shell does.
+func splitShellWords(s string) ([]string, error) {
+	words := []string{}
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("trailing backslash in %q", s)
+			}
+			i++
+			// Inside double quotes, a backslash only escapes the characters
+			// that are special there.
+			if quote == '"' && !strings.ContainsRune("$`\"\\\n", runes[i]) {
+				word.WriteRune(r)
+			}
+			if runes[i] != '\n' {
+				word.WriteRune(runes[i])
+			}
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
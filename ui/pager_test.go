@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "", want: []string{}},
+		{in: "less", want: []string{"less"}},
+		{in: "  less\t-R  ", want: []string{"less", "-R"}},
+		{in: `"/opt/my pager/bin/less" -R`, want: []string{"/opt/my pager/bin/less", "-R"}},
+		{in: `less --prompt='a "b" c'`, want: []string{"less", `--prompt=a "b" c`}},
+		{in: `less "a\"b" "\x" c\ d`, want: []string{"less", `a"b`, `\x`, "c d"}},
+		{in: `less ''`, want: []string{"less", ""}},
+		{in: `less; rm -rf ~`, want: []string{"less;", "rm", "-rf", "~"}},
+		{in: `less "unterminated`, wantErr: true},
+		{in: `less \`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := splitShellWords(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("splitShellWords(%q) = %q, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitShellWords(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Select asks the user to pick one of the options by number and returns the
+// index of the chosen option.
+func Select(message string, options []string) (int, error) {
+	Println(message)
+	for i, option := range options {
+		Printf("  %d) %s\n", i+1, option)
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		Printf("Choose an option [1-%d]: ", len(options))
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return 0, err
+			}
+			return 0, fmt.Errorf("no option selected")
+		}
+
+		choice, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+		if err == nil && choice >= 1 && choice <= len(options) {
+			return choice - 1, nil
+		}
+		Errorf("Invalid choice: %s\n", scanner.Text())
+	}
+}
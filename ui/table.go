@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"fmt"
+	"golang.org/x/crypto/ssh/terminal"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+const defaultTerminalWidth = 80
+
+func TerminalWidth(f *os.File) int {
+	if !IsTerminal(f) {
+		return defaultTerminalWidth
+	}
+	width, _, err := terminal.GetSize(int(f.Fd()))
+	if err != nil || width <= 0 {
+		return defaultTerminalWidth
+	}
+	return width
+}
+
+type tableField struct {
+	Text    string
+	ColorFn func(string) string
+}
+
+// TablePrinter renders rows of fields either as aligned, width-aware columns
+// for a terminal or as tab-separated values for scripts.
+type TablePrinter struct {
+	out      io.Writer
+	isTTY    bool
+	maxWidth int
+	rows     [][]tableField
+}
+
+func NewTablePrinter(w io.Writer, isTTY bool, maxWidth int) *TablePrinter {
+	return &TablePrinter{
+		out:      w,
+		isTTY:    isTTY,
+		maxWidth: maxWidth,
+	}
+}
+
+func (t *TablePrinter) IsTTY() bool {
+	return t.isTTY
+}
+
+func (t *TablePrinter) AddField(text string, colorFn func(string) string) {
+	if t.rows == nil {
+		t.rows = [][]tableField{{}}
+	}
+	rowI := len(t.rows) - 1
+	t.rows[rowI] = append(t.rows[rowI], tableField{Text: text, ColorFn: colorFn})
+}
+
+func (t *TablePrinter) EndRow() {
+	t.rows = append(t.rows, []tableField{})
+}
+
+func (t *TablePrinter) Render() error {
+	if len(t.rows) == 0 {
+		return nil
+	}
+
+	if !t.isTTY {
+		return t.renderTSV()
+	}
+
+	widths := t.columnWidths()
+	for _, row := range t.rows {
+		for col, field := range row {
+			text := truncate(widths[col], field.Text)
+			if col < len(row)-1 {
+				text += strings.Repeat(" ", widths[col]-displayWidth(text))
+			}
+			if field.ColorFn != nil && strings.TrimSpace(text) != "" {
+				text = field.ColorFn(text)
+			}
+			if col > 0 {
+				if _, err := fmt.Fprint(t.out, "  "); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprint(t.out, text); err != nil {
+				return err
+			}
+		}
+		if len(row) > 0 {
+			if _, err := fmt.Fprint(t.out, "\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *TablePrinter) renderTSV() error {
+	for _, row := range t.rows {
+		if len(row) == 0 {
+			continue
+		}
+		texts := make([]string, len(row))
+		for i, field := range row {
+			texts[i] = field.Text
+		}
+		if _, err := fmt.Fprintln(t.out, strings.Join(texts, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnWidths sizes each column to its widest field and then shrinks the
+// widest column until the row fits in the available width.
+func (t *TablePrinter) columnWidths() []int {
+	numCols := 0
+	for _, row := range t.rows {
+		if len(row) > numCols {
+			numCols = len(row)
+		}
+	}
+
+	widths := make([]int, numCols)
+	for _, row := range t.rows {
+		for col, field := range row {
+			if w := displayWidth(field.Text); w > widths[col] {
+				widths[col] = w
+			}
+		}
+	}
+
+	available := t.maxWidth - (numCols-1)*2
+	total := 0
+	widest := 0
+	for col, w := range widths {
+		total += w
+		if w > widths[widest] {
+			widest = col
+		}
+	}
+
+	if total > available {
+		widths[widest] -= total - available
+		if widths[widest] < minColumnWidth {
+			widths[widest] = minColumnWidth
+		}
+	}
+
+	return widths
+}
+
+const (
+	minColumnWidth = 5
+	ellipsis       = "..."
+)
+
+func truncate(maxWidth int, text string) string {
+	if displayWidth(text) <= maxWidth {
+		return text
+	}
+	if maxWidth <= len(ellipsis) {
+		return string([]rune(text)[:maxWidth])
+	}
+	return string([]rune(text)[:maxWidth-len(ellipsis)]) + ellipsis
+}
+
+func displayWidth(text string) int {
+	return utf8.RuneCountInString(text)
+}
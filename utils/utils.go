@@ -1,8 +1,13 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"github.com/npathai/github-cli-clone/ui"
 	"os"
+	"os/exec"
+	"runtime"
+	"strings"
 	"time"
 )
 
@@ -14,3 +19,76 @@ func Check(err error) {
 		os.Exit(1)
 	}
 }
+
+func BrowserLauncher() ([]string, error) {
+	browser := os.Getenv("BROWSER")
+	if browser == "" {
+		browser = searchBrowserLauncher(runtime.GOOS)
+	} else {
+		browser = os.ExpandEnv(browser)
+	}
+
+	if browser == "" {
+		return nil, errors.New("Please set $BROWSER to a web launcher")
+	}
+
+	return strings.Fields(browser), nil
+}
+
+func searchBrowserLauncher(goos string) (browser string) {
+	switch goos {
+	case "darwin":
+		browser = "open"
+	case "windows":
+		browser = "cmd /c start"
+	default:
+		candidates := []string{"xdg-open", "cygstart", "x-www-browser", "firefox",
+			"opera", "mozilla", "netscape"}
+		for _, b := range candidates {
+			path, err := exec.LookPath(b)
+			if err == nil {
+				browser = path
+				break
+			}
+		}
+	}
+
+	return browser
+}
+
+func OpenInBrowser(url string) error {
+	launcher, err := BrowserLauncher()
+	if err != nil {
+		return err
+	}
+
+	args := append(launcher[1:], url)
+	cmd := exec.Command(launcher[0], args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func TimeAgo(t time.Time) string {
+	ago := timeNow.Sub(t)
+
+	switch {
+	case ago < time.Minute:
+		return "just now"
+	case ago < time.Hour:
+		return pluralize(int(ago.Minutes()), "minute") + " ago"
+	case ago < 24*time.Hour:
+		return "about " + pluralize(int(ago.Hours()), "hour") + " ago"
+	case ago < 30*24*time.Hour:
+		return pluralize(int(ago.Hours()/24), "day") + " ago"
+	default:
+		return t.Format("Jan 2, 2006")
+	}
+}
+
+func pluralize(num int, thing string) string {
+	if num == 1 {
+		return fmt.Sprintf("%d %s", num, thing)
+	}
+	return fmt.Sprintf("%d %ss", num, thing)
+}